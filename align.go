@@ -0,0 +1,67 @@
+package tfrecord
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// paddingMagic starts payload of padding records written by WithAlignment.
+const paddingMagic = "TFRECPAD"
+
+// WithAlignment makes the writer align payload of every record to n bytes, counted from where the writer
+// starts writing, for mmap or DMA friendly layouts. TFRecord has no padding, so gaps are filled with padding
+// records: valid records whose payload is the 8 bytes "TFRECPAD" followed by zero bytes. A gap too small for
+// a padding record is extended by n until it fits, so a record takes at least 24 more bytes when it's not
+// already aligned. Standard readers, TensorFlow included, see padding records as regular records, read with
+// WithSkipPadding to skip them. Records with the same content as padding are skipped as well, they can't be
+// told apart. n less than 2 disables alignment.
+func WithAlignment(n int) WriterOption {
+	return func(w *Writer) {
+		w.align = n
+	}
+}
+
+// pad writes a padding record if payload of next record wouldn't be aligned.
+func (w *Writer) pad() error {
+	if w.align < 2 {
+		return nil
+	}
+	align := int64(w.align)
+	gap := (align - (w.offset+int64(w.headerLen()))%align) % align
+	if gap == 0 {
+		return nil
+	}
+	if minGap := int64(w.headerLen() + len(paddingMagic) + w.footerLen()); gap < minGap {
+		gap += (minGap - gap + align - 1) / align * align
+	}
+	if int64(cap(w.padBuf)) < gap {
+		w.padBuf = make([]byte, gap)
+	}
+	frame := w.padBuf[:gap]
+	clear(frame)
+	header := w.putHeader(frame, uint64(int(gap)-w.headerLen()-w.footerLen()))
+	payload := frame[len(header) : int(gap)-w.footerLen()]
+	copy(payload, paddingMagic)
+	if w.footerLen() > 0 {
+		binary.LittleEndian.PutUint32(frame[len(frame)-FooterSize:], checksum(payload))
+	}
+	if err := writeFull(w.w, frame); err != nil {
+		return err
+	}
+	w.offset += gap
+	return nil
+}
+
+// WithSkipPadding makes the iterator skip padding records written with WithAlignment, they don't count as
+// records in State or for WithExpectedCount. NextStream doesn't skip them.
+func WithSkipPadding() Option {
+	return func(it *Iterator) {
+		it.skipPadding = true
+	}
+}
+
+// isPadding reports whether record is a padding record.
+func isPadding(record []byte) bool {
+	rest, ok := bytes.CutPrefix(record, []byte(paddingMagic))
+	return ok && len(bytes.TrimLeft(rest, "\x00")) == 0
+}
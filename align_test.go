@@ -0,0 +1,52 @@
+package tfrecord
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAlignment(t *testing.T) {
+	sizes := []int{0, 1, 7, 20, 64, 100, 5000}
+	for _, opts := range [][]WriterOption{{WithAlignment(64)}, {WithAlignment(8), WithRawWrites()}, {WithAlignment(4096)}} {
+		var buf bytes.Buffer
+		w := NewWriter(&buf, opts...)
+		raw := w.raw
+		for _, size := range sizes {
+			if _, err := w.Write(bytes.Repeat([]byte{1}, size)); err != nil {
+				t.Fatalf("write error %v", err)
+			}
+		}
+		if _, err := w.WriteFrom(bytes.NewReader(make([]byte, 10)), 10); err != nil {
+			t.Fatalf("write error %v", err)
+		}
+		readOpts := []Option{WithSkipPadding()}
+		headerLen := int64(HeaderSize)
+		if raw {
+			readOpts, headerLen = append(readOpts, WithRawReads()), LengthSize
+		}
+		it := NewIterator(bytes.NewReader(buf.Bytes()), 16, true, readOpts...)
+		n := 0
+		for r, ok := it.NextRecord(); ok; r, ok = it.NextRecord() {
+			if (r.Offset+headerLen)%int64(w.align) != 0 {
+				t.Errorf("record %d payload at %d not aligned to %d", n, r.Offset+headerLen, w.align)
+			}
+			n++
+		}
+		if it.Err() != nil || n != len(sizes)+1 {
+			t.Errorf("expect %d records skipping padding, actual %d, err %v", len(sizes)+1, n, it.Err())
+		}
+		if raw {
+			continue
+		}
+		it = NewIterator(bytes.NewReader(buf.Bytes()), 16, true)
+		padding := 0
+		for it.Next() {
+			if isPadding(it.Value()) {
+				padding++
+			}
+		}
+		if it.Err() != nil || padding == 0 {
+			t.Errorf("expect valid padding records for standard readers, actual %d, err %v", padding, it.Err())
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package tfrecord
+
+// Allocator provides record buffers to an Iterator, for services managing memory on their own like slab or
+// off-heap allocators. Get returns a buffer of length n, Put takes back a buffer from Get once the iterator is
+// done with it.
+type Allocator interface {
+	Get(n int) []byte
+	Put([]byte)
+}
+
+// WithAllocator makes the iterator get buffers from a instead of allocating them, both when growing its buffer
+// and for records larger than it. Such a record is only valid until next read, like records in the iterator
+// buffer, it's then put back to a. Close puts back all outstanding buffers. Buffers for WithReadahead are still
+// allocated since they are held across reads.
+func WithAllocator(a Allocator) Option {
+	return func(it *Iterator) {
+		it.allocator = a
+	}
+}
+
+// growBuf replaces preBuf with a buffer of n bytes.
+func (it *Iterator) growBuf(n int) {
+	if it.allocator == nil {
+		it.preBuf = make([]byte, n)
+		return
+	}
+	it.releaseBuf()
+	it.preBuf, it.preBufAllocated = it.allocator.Get(n), true
+}
+
+// releaseBuf puts preBuf back to allocator if it's from allocator.
+func (it *Iterator) releaseBuf() {
+	if it.preBufAllocated {
+		it.allocator.Put(it.preBuf)
+	}
+	it.preBuf, it.preBufAllocated = nil, false
+}
+
+// releaseBorrowed puts buffer of previous oversize record back to allocator.
+func (it *Iterator) releaseBorrowed() {
+	if it.borrowed != nil {
+		it.allocator.Put(it.borrowed)
+		it.borrowed = nil
+	}
+}
+
+// Close stops the iterator and puts buffers from WithAllocator back, Next returns false afterwards while Err
+// keeps reporting the error iteration stopped with, if any. With WithAutoClose it closes the underlying reader
+// if not closed yet, returning its close error.
+func (it *Iterator) Close() error {
+	if it.closed {
+		return nil
+	}
+	it.closed, it.value, it.stream = true, nil, nil
+	it.part = partialFrame{}
+	err := it.err
+	it.finish()
+	it.releaseBorrowed()
+	it.releaseBuf()
+	if it.err != err {
+		return it.err
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package tfrecord
+
+import (
+	"bytes"
+	"testing"
+)
+
+// countingAllocator tracks buffers it handed out and not put back yet.
+type countingAllocator struct {
+	gets        int
+	outstanding map[*byte]int
+}
+
+func (a *countingAllocator) Get(n int) []byte {
+	b := make([]byte, n)
+	a.gets++
+	a.outstanding[&b[:1][0]] = n
+	return b
+}
+
+func (a *countingAllocator) Put(b []byte) {
+	if _, ok := a.outstanding[&b[:1][0]]; !ok {
+		panic("put buffer not from allocator")
+	}
+	delete(a.outstanding, &b[:1][0])
+}
+
+func TestAllocator(t *testing.T) {
+	data := writeSizedRecords(t, 4, 100, 8, 200, 4)
+	a := &countingAllocator{outstanding: map[*byte]int{}}
+	it := NewIterator(bytes.NewReader(data), 16, true, WithAllocator(a))
+	var sizes []int
+	for it.Next() {
+		sizes = append(sizes, len(it.Value()))
+		if it.ValueOwned() {
+			t.Errorf("expect record from allocator not owned")
+		}
+		if len(a.outstanding) > 1 {
+			t.Errorf("expect at most 1 outstanding buffer, actual %d", len(a.outstanding))
+		}
+	}
+	if it.Err() != nil || len(sizes) != 5 {
+		t.Fatalf("expect 5 records, actual %v, err %v", sizes, it.Err())
+	}
+	if a.gets != 2 {
+		t.Errorf("expect 2 buffers for oversize records, actual %d", a.gets)
+	}
+	if err := it.Close(); err != nil || len(a.outstanding) != 0 {
+		t.Errorf("expect all buffers back after Close, actual %d, err %v", len(a.outstanding), err)
+	}
+
+	a = &countingAllocator{outstanding: map[*byte]int{}}
+	it = NewIterator(bytes.NewReader(data), 0, true, WithAllocator(a))
+	if !it.Next() || !it.Next() || len(it.Value()) != 100 {
+		t.Fatalf("expect 2 records, err %v", it.Err())
+	}
+	if len(a.outstanding) != 1 {
+		t.Errorf("expect grown buffer outstanding, actual %d", len(a.outstanding))
+	}
+	if err := it.Close(); err != nil || len(a.outstanding) != 0 {
+		t.Errorf("expect all buffers back after Close, actual %d, err %v", len(a.outstanding), err)
+	}
+	if it.Next() || it.Err() != nil {
+		t.Errorf("expect no record after Close, err %v", it.Err())
+	}
+}
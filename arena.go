@@ -0,0 +1,45 @@
+package tfrecord
+
+import (
+	"fmt"
+	"io"
+)
+
+// ReadAllInto reads all records of r into arena, packed one after another, and returns them as sub-slices of
+// arena, records are read in place so no per-record buffer is allocated. When arena is too small it still
+// scans r to the end and returns an error wrapping io.ErrShortBuffer reporting the arena size needed, total
+// payload size, which also comes from an index as sum of lengths.
+func ReadAllInto(r io.Reader, arena []byte, checkDataCRC bool) ([][]byte, error) {
+	it := NewIterator(r, 0, checkDataCRC)
+	var (
+		records [][]byte
+		used    uint64
+	)
+	for {
+		payload, length, err := it.NextStream()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return records, err
+		}
+		if used+length > uint64(len(arena)) {
+			used += length
+			continue
+		}
+		record := arena[used : used+length : used+length]
+		if _, err := io.ReadFull(payload, record); err != nil {
+			return records, err
+		}
+		// reads footer and checks CRC.
+		if _, err := payload.Read(nil); err != io.EOF {
+			return records, err
+		}
+		records = append(records, record)
+		used += length
+	}
+	if used > uint64(len(arena)) {
+		return nil, fmt.Errorf("records need arena of %d bytes, got %d: %w", used, len(arena), io.ErrShortBuffer)
+	}
+	return records, it.Err()
+}
@@ -0,0 +1,34 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestReadAllInto(t *testing.T) {
+	data := writeSizedRecords(t, 3, 0, 100000, 5)
+	arena := make([]byte, 100008)
+	records, err := ReadAllInto(bytes.NewReader(data), arena, true)
+	if err != nil || len(records) != 4 {
+		t.Fatalf("expect 4 records, actual %d, %v", len(records), err)
+	}
+	offset := 0
+	for i, size := range []int{3, 0, 100000, 5} {
+		if len(records[i]) != size || (size > 0 && &records[i][0] != &arena[offset]) {
+			t.Errorf("record %d of %d bytes not packed in arena", i, len(records[i]))
+		}
+		offset += size
+	}
+
+	_, err = ReadAllInto(bytes.NewReader(data), arena[:100], true)
+	if !errors.Is(err, io.ErrShortBuffer) || err.Error() != "records need arena of 100008 bytes, got 100: short buffer" {
+		t.Errorf("expect io.ErrShortBuffer with needed size, actual %v", err)
+	}
+
+	data[len(data)-FooterSize-1]++
+	if _, err := ReadAllInto(bytes.NewReader(data), arena, true); !errors.Is(err, ErrChecksum) {
+		t.Errorf("expect ErrChecksum, actual %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package tfrecord
+
+import (
+	"context"
+)
+
+// WriteFromChannel writes records received from ch to w until ch is closed or ctx is canceled, it returns the
+// number of records written. On cancellation it returns ctx.Err() once the record being written, if any, is
+// done, records still queued in ch are left unwritten. A record received from ch is always written, none is
+// lost on cancellation.
+func WriteFromChannel(ctx context.Context, w *Writer, ch <-chan []byte) (int, error) {
+	n := 0
+	for {
+		// checked first, select picks randomly when both ctx and ch are ready.
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		select {
+		case <-ctx.Done():
+			return n, ctx.Err()
+		case record, ok := <-ch:
+			if !ok {
+				return n, nil
+			}
+			if _, err := w.Write(record); err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package tfrecord
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestWriteFromChannel(t *testing.T) {
+	ch := make(chan []byte)
+	go func() {
+		for i := 0; i < 10; i++ {
+			ch <- []byte(strconv.Itoa(i))
+		}
+		close(ch)
+	}()
+	buf := &bytes.Buffer{}
+	n, err := WriteFromChannel(context.Background(), NewWriter(buf), ch)
+	if n != 10 || err != nil {
+		t.Fatalf("expect 10 records written, actual %d, err %v", n, err)
+	}
+	if !bytes.Equal(buf.Bytes(), writeTestRecords(t, 10)) {
+		t.Errorf("unmatched written content")
+	}
+}
+
+// notifyWriter signals on written after every write.
+type notifyWriter struct {
+	bytes.Buffer
+	written chan struct{}
+}
+
+func (w *notifyWriter) Write(p []byte) (int, error) {
+	n, err := w.Buffer.Write(p)
+	w.written <- struct{}{}
+	return n, err
+}
+
+func TestWriteFromChannelCancel(t *testing.T) {
+	ch := make(chan []byte, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	dst := &notifyWriter{written: make(chan struct{}, 100)}
+	w := NewWriter(dst)
+	done := make(chan struct{})
+	var (
+		n   int
+		err error
+	)
+	go func() {
+		n, err = WriteFromChannel(ctx, w, ch)
+		close(done)
+	}()
+	for i := 0; i < 2; i++ {
+		ch <- []byte(strconv.Itoa(i))
+		// small records are written in a single write.
+		<-dst.written
+	}
+	cancel()
+	<-done
+	if n != 2 || err != context.Canceled {
+		t.Errorf("expect 2 records then canceled, actual %d, err %v", n, err)
+	}
+
+	ch <- []byte("2")
+	if n, err := WriteFromChannel(ctx, w, ch); n != 0 || err != context.Canceled {
+		t.Errorf("expect nothing written after cancel, actual %d, err %v", n, err)
+	}
+	if !bytes.Equal(dst.Bytes(), writeTestRecords(t, 2)) {
+		t.Errorf("unmatched written content")
+	}
+}
+
+// lateCancelCtx is canceled, but only reports it from the second Err call, like a context canceled right
+// after WriteFromChannel checked it.
+type lateCancelCtx struct {
+	context.Context
+	checked bool
+}
+
+func (c *lateCancelCtx) Err() error {
+	if !c.checked {
+		c.checked = true
+		return nil
+	}
+	return c.Context.Err()
+}
+
+func TestWriteFromChannelCancelKeepsReceived(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 20; i++ {
+		ch := make(chan []byte, 1)
+		ch <- []byte("0")
+		buf := &bytes.Buffer{}
+		n, err := WriteFromChannel(&lateCancelCtx{Context: canceled}, NewWriter(buf), ch)
+		if err != context.Canceled || n+len(ch) != 1 {
+			t.Fatalf("expect record written or left in channel, actual %d written, %d queued, err %v", n, len(ch), err)
+		}
+		if n == 1 && !bytes.Equal(buf.Bytes(), writeTestRecords(t, 1)) {
+			t.Errorf("unmatched written content")
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package tfrecord
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// IteratorStateVersion is the version of IteratorState produced by this package.
+const IteratorStateVersion = 1
+
+// IteratorState is a checkpoint of an Iterator, it can be persisted with MarshalBinary and passed to
+// ResumeIterator to continue iteration later.
+type IteratorState struct {
+	// Version is the format version of the state, ResumeIterator rejects versions it doesn't know.
+	Version int
+	// Offset is the position of next record, relative to where the iterator started reading.
+	Offset int64
+	// Ordinal is the number of records read before this state.
+	Ordinal int64
+}
+
+// State returns checkpoint of the iterator, pointing to the record after current one.
+func (it *Iterator) State() IteratorState {
+	return IteratorState{Version: IteratorStateVersion, Offset: it.offset, Ordinal: it.ordinal}
+}
+
+// ResumeIterator creates an Iterator continuing from state. r is seeked to state.Offset from its start, so it
+// must be the same content the checkpointed iterator read from start.
+func ResumeIterator(r io.ReadSeeker, state IteratorState, bufSize int64, checkDataCRC bool, opts ...Option) (*Iterator, error) {
+	if err := state.check(); err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(state.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	it := NewIterator(r, bufSize, checkDataCRC, opts...)
+	it.offset, it.readOffset, it.baseOffset, it.ordinal = state.Offset, state.Offset, state.Offset, state.Ordinal
+	return it, nil
+}
+
+func (s IteratorState) check() error {
+	if s.Version != IteratorStateVersion {
+		return fmt.Errorf("iterator state version %d, supports %d: %w", s.Version, IteratorStateVersion, ErrInvalidState)
+	}
+	if s.Offset < 0 || s.Ordinal < 0 {
+		return fmt.Errorf("iterator state offset %d, ordinal %d: %w", s.Offset, s.Ordinal, ErrInvalidState)
+	}
+	return nil
+}
+
+// ErrInvalidState is error returned for iterator state of unknown version or malformed content.
+var ErrInvalidState = errors.New("invalid TFRecord iterator state")
+
+// MarshalBinary implements encoding.BinaryMarshaler, fields are encoded as varints led by version.
+func (s IteratorState) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, 3*binary.MaxVarintLen64)
+	buf = binary.AppendUvarint(buf, uint64(s.Version))
+	buf = binary.AppendUvarint(buf, uint64(s.Offset))
+	buf = binary.AppendUvarint(buf, uint64(s.Ordinal))
+	return buf, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, it rejects unknown versions.
+func (s *IteratorState) UnmarshalBinary(data []byte) error {
+	var fields [3]uint64
+	for i := range fields {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("truncated iterator state: %w", ErrInvalidState)
+		}
+		fields[i], data = v, data[n:]
+		if i == 0 && v != IteratorStateVersion {
+			return fmt.Errorf("iterator state version %d, supports %d: %w", v, IteratorStateVersion, ErrInvalidState)
+		}
+	}
+	if len(data) != 0 || fields[1] > maxInt64 || fields[2] > maxInt64 {
+		return fmt.Errorf("malformed iterator state: %w", ErrInvalidState)
+	}
+	*s = IteratorState{Version: int(fields[0]), Offset: int64(fields[1]), Ordinal: int64(fields[2])}
+	return nil
+}
@@ -0,0 +1,58 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestResumeIterator(t *testing.T) {
+	data := writeTestRecords(t, 10)
+	it := NewIterator(bytes.NewReader(data), 16, true)
+	for i := 0; i < 4; i++ {
+		it.Next()
+	}
+	encoded, err := it.State().MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed marshaling state %v", err)
+	}
+
+	var state IteratorState
+	if err := state.UnmarshalBinary(encoded); err != nil {
+		t.Fatalf("failed unmarshaling state %v", err)
+	}
+	if state != it.State() || state.Ordinal != 4 {
+		t.Errorf("unmatched state, expect %+v, actual %+v", it.State(), state)
+	}
+	resumed, err := ResumeIterator(bytes.NewReader(data), state, 16, true)
+	if err != nil {
+		t.Fatalf("failed resuming %v", err)
+	}
+	out := ""
+	for resumed.Next() {
+		out += string(resumed.Value())
+	}
+	if resumed.Err() != nil || out != "456789" {
+		t.Errorf("unexpected resumed content %s, err %v", out, resumed.Err())
+	}
+	if s := resumed.State(); s.Ordinal != 10 || s.Offset != int64(len(data)) {
+		t.Errorf("unexpected final state %+v", s)
+	}
+}
+
+func TestIteratorStateVersion(t *testing.T) {
+	state := IteratorState{Version: IteratorStateVersion + 1, Offset: 10}
+	if _, err := ResumeIterator(bytes.NewReader(nil), state, 16, true); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("expect ErrInvalidState, actual %v", err)
+	}
+	encoded, _ := state.MarshalBinary()
+	if err := new(IteratorState).UnmarshalBinary(encoded); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("expect ErrInvalidState unmarshaling unknown version, actual %v", err)
+	}
+	encoded, _ = IteratorState{Version: IteratorStateVersion, Offset: 300}.MarshalBinary()
+	for _, data := range [][]byte{nil, encoded[:2], append(encoded, 0)} {
+		if err := new(IteratorState).UnmarshalBinary(data); !errors.Is(err, ErrInvalidState) {
+			t.Errorf("expect ErrInvalidState unmarshaling %v, actual %v", data, err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package tfrecord
+
+import (
+	"io"
+)
+
+// ByteRange is a range [Start, End) of byte positions in a file.
+type ByteRange struct {
+	Start, End int64
+}
+
+// ChunkBoundaries splits r from its current position to the end into contiguous chunks of whole records, for
+// handing off to workers, each reading its chunk with NewIteratorAt(io.NewSectionReader(f, 0, chunk.End),
+// chunk.Start, ...) without an index.
+// Chunks are filled up to targetBytes, framing included, a record larger than targetBytes gets a chunk of its
+// own. Only headers are read like Count, payloads are skipped by seeking. Positions are absolute positions in
+// r, r is left at its end.
+func ChunkBoundaries(r io.ReadSeeker, targetBytes int64) ([]ByteRange, error) {
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	var chunks []ByteRange
+	cur := ByteRange{Start: start, End: start}
+	err = scanHeaders(r, func(length uint64) error {
+		size := FrameSize(int(length))
+		if cur.End > cur.Start && cur.End-cur.Start+size > targetBytes {
+			chunks = append(chunks, cur)
+			cur.Start = cur.End
+		}
+		cur.End += size
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cur.End > cur.Start {
+		chunks = append(chunks, cur)
+	}
+	return chunks, nil
+}
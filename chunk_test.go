@@ -0,0 +1,50 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestChunkBoundaries(t *testing.T) {
+	data := writeSizedRecords(t, 10, 10, 10, 100, 10, 10)
+	r := bytes.NewReader(append([]byte("junk"), data...))
+	r.Seek(4, io.SeekStart)
+	chunks, err := ChunkBoundaries(r, 2*FrameSize(10))
+	if err != nil {
+		t.Fatalf("chunk error %v", err)
+	}
+	small, big := FrameSize(10), FrameSize(100)
+	expect := []ByteRange{
+		{4, 4 + 2*small},
+		{4 + 2*small, 4 + 3*small},
+		{4 + 3*small, 4 + 3*small + big},
+		{4 + 3*small + big, 4 + 5*small + big},
+	}
+	if !reflect.DeepEqual(chunks, expect) {
+		t.Fatalf("expect chunks %v, actual %v", expect, chunks)
+	}
+
+	n := 0
+	for _, c := range chunks {
+		it := NewIteratorAt(io.NewSectionReader(r, 0, c.End), c.Start, 0, true)
+		for it.Next() {
+			n++
+		}
+		if it.Err() != nil {
+			t.Errorf("read chunk %v error %v", c, it.Err())
+		}
+	}
+	if n != 6 {
+		t.Errorf("expect 6 records across chunks, actual %d", n)
+	}
+
+	if chunks, err := ChunkBoundaries(bytes.NewReader(nil), 100); err != nil || len(chunks) != 0 {
+		t.Errorf("expect no chunk for empty file, actual %v, %v", chunks, err)
+	}
+	if _, err := ChunkBoundaries(bytes.NewReader(data[:len(data)-1]), 100); !errors.Is(err, ErrTruncated) {
+		t.Errorf("expect ErrTruncated, actual %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package tfrecord
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrUnrecoverable is error returned by ClearErr when iteration can't continue after the current error.
+var ErrUnrecoverable = errors.New("unrecoverable TFRecord iterator error")
+
+// clearMode is how ClearErr recovers from current error.
+type clearMode int
+
+const (
+	notClearable clearMode = iota
+	// clearAtBoundary clears errors found after the whole frame is read, like data CRC mismatch.
+	clearAtBoundary
+	// clearSkipRecord clears errors found after the header is read by skipping the rest of the frame.
+	clearSkipRecord
+)
+
+// ClearErr acknowledges the current error and lets iteration continue with the next record. It's only valid
+// when stream position is known to be good: after a data CRC failure, the corrupt record is dropped, and after
+// a record exceeding buffer limits, ErrBufferTooSmall or ErrRecordTooLarge, the record is skipped. For other
+// errors, where continuing would read garbage, like length CRC failure, truncation or IO errors, it returns an
+// error wrapping ErrUnrecoverable and the iterator keeps its error. It's also unrecoverable when WithAutoClose
+// closed the reader already. ClearErr is a no-op returning nil when there's no error.
+func (it *Iterator) ClearErr() error {
+	if it.err == nil {
+		return nil
+	}
+	if it.clearable == notClearable || (it.autoClose && it.finished) {
+		return fmt.Errorf("clear error %q: %w", it.err, ErrUnrecoverable)
+	}
+	if it.clearable == clearSkipRecord {
+		rest := int64(it.part.length) + int64(it.footerLen())
+		if _, err := io.CopyN(io.Discard, it.in, rest); err != nil {
+			if err == io.EOF {
+				err = &RecordError{Offset: it.readOffset - int64(it.headerLen()), Err: ErrTruncated}
+			}
+			it.err, it.clearable = err, notClearable
+			return fmt.Errorf("skip record: %w", err)
+		}
+		it.readOffset += rest
+		it.part = partialFrame{}
+	}
+	it.err, it.aheadErr, it.clearable = nil, nil, notClearable
+	it.finished = false
+	it.offset = it.readOffset
+	return nil
+}
@@ -0,0 +1,53 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestClearErr(t *testing.T) {
+	data := writeSizedRecords(t, 1, 2, 20, 3)
+	data[FrameSize(1)+HeaderSize]++
+	it := NewIterator(bytes.NewReader(data), 16, true)
+	var sizes []int
+	for {
+		record, ok := it.NextReuse()
+		if ok {
+			sizes = append(sizes, len(record))
+			continue
+		}
+		if it.Err() == nil {
+			break
+		}
+		if err := it.ClearErr(); err != nil {
+			t.Fatalf("unexpected clear error %v", err)
+		}
+	}
+	// corrupt and oversize records are dropped.
+	if len(sizes) != 2 || sizes[0] != 1 || sizes[1] != 3 {
+		t.Errorf("unexpected records %v", sizes)
+	}
+	if s := it.State(); s.Offset != int64(len(data)) {
+		t.Errorf("expect offset %d, actual %d", len(data), s.Offset)
+	}
+	if it.ClearErr() != nil {
+		t.Errorf("expect nil clearing no error")
+	}
+
+	for _, c := range []struct {
+		name string
+		data func() []byte
+	}{
+		{"length crc", func() []byte { d := writeTestRecords(t, 3); d[FrameSize(1)]++; return d }},
+		{"truncated", func() []byte { return writeTestRecords(t, 3)[:FrameSize(1)+5] }},
+	} {
+		it := NewIterator(bytes.NewReader(c.data()), 16, true)
+		for it.Next() {
+		}
+		err := it.Err()
+		if cerr := it.ClearErr(); !errors.Is(cerr, ErrUnrecoverable) || it.Err() != err {
+			t.Errorf("%s: expect ErrUnrecoverable keeping error, actual %v, %v", c.name, cerr, it.Err())
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package tfrecord
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+)
+
+// CompressionType is compression of a TFRecord file as a whole, like TFRecordOptions.compression_type of
+// TensorFlow.
+type CompressionType int
+
+const (
+	// NoCompression is plain TFRecord.
+	NoCompression CompressionType = iota
+	// GzipCompression is TFRecord in gzip format, "GZIP" in TensorFlow.
+	GzipCompression
+	// ZlibCompression is TFRecord in zlib format, "ZLIB" in TensorFlow.
+	ZlibCompression
+)
+
+func (c CompressionType) String() string {
+	switch c {
+	case NoCompression:
+		return "none"
+	case GzipCompression:
+		return "gzip"
+	case ZlibCompression:
+		return "zlib"
+	}
+	return fmt.Sprintf("CompressionType(%d)", int(c))
+}
+
+// WithCompression makes the iterator decompress the stream with c before decoding records. Offsets the
+// iterator reports are positions in the decompressed stream. Writer doesn't compress, wrap its destination in
+// a gzip.Writer or zlib.Writer for that.
+func WithCompression(c CompressionType) Option {
+	return func(it *Iterator) {
+		it.compression = c
+	}
+}
+
+// initCompression puts a decompressor on top of it.in, it goes beneath buffering and readahead.
+func (it *Iterator) initCompression() {
+	if it.compression == NoCompression {
+		return
+	}
+	it.decompressor = &decompressReader{compression: it.compression, src: &byteCounter{r: it.in}}
+	it.in = it.decompressor
+}
+
+// CompressionStats returns number of bytes consumed from the compressed stream and number of bytes produced
+// from it by decompression, for monitoring compression ratio. Decompression reads ahead, so both can be a bit
+// ahead of records read. For uncompressed iterators both are bytes of records consumed.
+func (it *Iterator) CompressionStats() (compressed int64, uncompressed int64) {
+	if it.decompressor == nil {
+		n := it.readOffset - it.baseOffset
+		return n, n
+	}
+	return it.decompressor.src.n, it.decompressor.n
+}
+
+// byteCounter counts bytes read through it.
+type byteCounter struct {
+	r io.Reader
+	n int64
+}
+
+func (c *byteCounter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// decompressReader decompresses src, creating the decompressor on first read since gzip and zlib read header
+// on creation.
+type decompressReader struct {
+	compression CompressionType
+	src         *byteCounter
+	r           io.Reader
+	n           int64
+	err         error
+}
+
+func (d *decompressReader) Read(p []byte) (int, error) {
+	if d.err != nil {
+		return 0, d.err
+	}
+	if d.r == nil {
+		var err error
+		switch d.compression {
+		case GzipCompression:
+			d.r, err = gzip.NewReader(d.src)
+		case ZlibCompression:
+			d.r, err = zlib.NewReader(d.src)
+		default:
+			err = fmt.Errorf("unknown TFRecord compression %v", d.compression)
+		}
+		if err != nil {
+			d.err = fmt.Errorf("%v stream: %w", d.compression, err)
+			if err == io.EOF {
+				// Empty stream, no records.
+				d.err = io.EOF
+			}
+			return 0, d.err
+		}
+	}
+	n, err := d.r.Read(p)
+	d.n += int64(n)
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("%v stream: %w", d.compression, err)
+	}
+	return n, err
+}
@@ -0,0 +1,65 @@
+package tfrecord
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"testing"
+)
+
+func compress(t testing.TB, c CompressionType, data []byte) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch c {
+	case GzipCompression:
+		w = gzip.NewWriter(&buf)
+	case ZlibCompression:
+		w = zlib.NewWriter(&buf)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompression(t *testing.T) {
+	data := writeSizedRecords(t, make([]int, 100)...)
+	for _, c := range []CompressionType{GzipCompression, ZlibCompression} {
+		compressed := compress(t, c, data)
+		it := NewIterator(bytes.NewReader(compressed), 16, true, WithCompression(c))
+		n := 0
+		for it.Next() {
+			n++
+		}
+		if it.Err() != nil || n != 100 {
+			t.Fatalf("expect 100 %v records, actual %d, err %v", c, n, it.Err())
+		}
+		if in, out := it.CompressionStats(); in != int64(len(compressed)) || out != int64(len(data)) {
+			t.Errorf("expect %v stats %d, %d, actual %d, %d", c, len(compressed), len(data), in, out)
+		}
+	}
+
+	it := NewIterator(bytes.NewReader(nil), 16, true, WithCompression(GzipCompression))
+	if it.Next() || it.Err() != nil {
+		t.Errorf("expect empty gzip stream to end cleanly, err %v", it.Err())
+	}
+	it = NewIterator(bytes.NewReader(data), 16, true, WithCompression(GzipCompression))
+	if it.Next() || it.Err() == nil {
+		t.Errorf("expect error reading uncompressed data as gzip")
+	}
+}
+
+func TestCompressionStatsUncompressed(t *testing.T) {
+	data := writeSizedRecords(t, 3, 5, 7)
+	it := NewIteratorAt(bytes.NewReader(data), FrameSize(3), 16, true)
+	if !it.Next() {
+		t.Fatalf("expect a record, err %v", it.Err())
+	}
+	if in, out := it.CompressionStats(); in != FrameSize(5) || out != in {
+		t.Errorf("expect stats %d, %d, actual %d, %d", FrameSize(5), FrameSize(5), in, out)
+	}
+}
@@ -0,0 +1,118 @@
+package tfrecord
+
+import (
+	"hash/crc32"
+	"sync"
+)
+
+// parallelCRCMinSize is the minimal record size for WithParallelCRC to split CRC computation.
+const parallelCRCMinSize = 1 << 20
+
+// WithParallelCRC computes data CRC of records larger than 1MB in chunks parallel goroutines, partial CRCs
+// are combined so the result is identical to serial computation. It speeds up checking very large records,
+// chunks less than 2 disables it.
+func WithParallelCRC(chunks int) Option {
+	return func(it *Iterator) {
+		it.crcChunks = chunks
+	}
+}
+
+// dataChecksum returns masked CRC of record, computed in parallel when configured.
+func (it *Iterator) dataChecksum(record []byte) uint32 {
+	if it.crcChunks < 2 || len(record) < parallelCRCMinSize {
+		return checksum(record)
+	}
+	return maskCRC(parallelCRC(record, it.crcChunks))
+}
+
+// HardwareCRCAvailable reports whether hash/crc32 uses CPU instructions to compute CRC-32C on current platform.
+// It mirrors the CPU feature checks of hash/crc32, CRC throughput is much lower without hardware support.
+func HardwareCRCAvailable() bool {
+	return hardwareCRC()
+}
+
+// parallelCRC computes CRC-32C of p by splitting it in chunks computed in parallel.
+func parallelCRC(p []byte, chunks int) uint32 {
+	chunkSize := (len(p) + chunks - 1) / chunks
+	if chunkSize == 0 {
+		return crc32.Checksum(p, crc32Table)
+	}
+	// parts is allocated upfront, goroutines write to it while later chunks are started.
+	parts := make([]uint32, (len(p)+chunkSize-1)/chunkSize)
+	var wg sync.WaitGroup
+	for i := range parts {
+		start := i * chunkSize
+		end := min(start+chunkSize, len(p))
+		wg.Add(1)
+		go func(i int, chunk []byte) {
+			defer wg.Done()
+			parts[i] = crc32.Checksum(chunk, crc32Table)
+		}(i, p[start:end])
+	}
+	wg.Wait()
+
+	crc := crc32.Checksum(nil, crc32Table)
+	for i, part := range parts {
+		size := chunkSize
+		if i == len(parts)-1 {
+			size = len(p) - chunkSize*i
+		}
+		crc = crc32Combine(crc, part, int64(size))
+	}
+	return crc
+}
+
+// crc32Combine returns CRC-32C of concatenated A and B given crc1 of A, crc2 of B and length of B. It's the
+// GF(2) matrix method of zlib's crc32_combine.
+func crc32Combine(crc1, crc2 uint32, len2 int64) uint32 {
+	if len2 <= 0 {
+		return crc1
+	}
+	var even, odd [32]uint32
+	// odd is the operator for one zero bit.
+	odd[0] = crc32.Castagnoli
+	row := uint32(1)
+	for n := 1; n < 32; n++ {
+		odd[n] = row
+		row <<= 1
+	}
+	gf2MatrixSquare(&even, &odd) // two zero bits
+	gf2MatrixSquare(&odd, &even) // four zero bits
+
+	// apply len2 zero bytes to crc1, first square puts operator for one zero byte, eight zero bits, in even.
+	for {
+		gf2MatrixSquare(&even, &odd)
+		if len2&1 != 0 {
+			crc1 = gf2MatrixTimes(&even, crc1)
+		}
+		len2 >>= 1
+		if len2 == 0 {
+			break
+		}
+		gf2MatrixSquare(&odd, &even)
+		if len2&1 != 0 {
+			crc1 = gf2MatrixTimes(&odd, crc1)
+		}
+		len2 >>= 1
+		if len2 == 0 {
+			break
+		}
+	}
+	return crc1 ^ crc2
+}
+
+func gf2MatrixTimes(mat *[32]uint32, vec uint32) uint32 {
+	var sum uint32
+	for i := 0; vec != 0; i, vec = i+1, vec>>1 {
+		if vec&1 != 0 {
+			sum ^= mat[i]
+		}
+	}
+	return sum
+}
+
+func gf2MatrixSquare(square, mat *[32]uint32) {
+	for n := 0; n < 32; n++ {
+		square[n] = gf2MatrixTimes(mat, mat[n])
+	}
+}
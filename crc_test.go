@@ -0,0 +1,82 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"hash/crc32"
+	"math/rand"
+	"os"
+	"regexp"
+	"runtime"
+	"testing"
+)
+
+func TestParallelCRC(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, size := range []int{0, 1, 7, 1000, 1 << 20, 3<<20 + 17} {
+		p := make([]byte, size)
+		rng.Read(p)
+		expect := crc32.Checksum(p, crc32Table)
+		for _, chunks := range []int{1, 2, 3, 8, 64} {
+			if actual := parallelCRC(p, chunks); actual != expect {
+				t.Errorf("size %d chunks %d: expect %#x, actual %#x", size, chunks, expect, actual)
+			}
+		}
+	}
+}
+
+func TestWithParallelCRC(t *testing.T) {
+	data := writeSizedRecords(t, 10, 2<<20+3, 5)
+	it := NewIterator(bytes.NewReader(data), 16, true, WithParallelCRC(4))
+	n := 0
+	for it.Next() {
+		n++
+	}
+	if err := it.Err(); err != nil || n != 3 {
+		t.Errorf("expect 3 records, actual %d, err %v", n, err)
+	}
+
+	data[HeaderSize+10+FooterSize+HeaderSize+1<<20]++
+	it = NewIterator(bytes.NewReader(data), 16, true, WithParallelCRC(4))
+	for it.Next() {
+	}
+	var re *RecordError
+	if !errors.As(it.Err(), &re) || re.StoredCRC != checksum(bytes.Repeat([]byte("x"), 2<<20+3)) {
+		t.Errorf("expect checksum error, actual %v", it.Err())
+	}
+}
+
+func benchmarkCRC(b *testing.B, chunks int) {
+	p := make([]byte, 64<<20)
+	rand.New(rand.NewSource(1)).Read(p)
+	it := &Iterator{crcChunks: chunks}
+	b.SetBytes(int64(len(p)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		it.dataChecksum(p)
+	}
+}
+
+func BenchmarkCRCSerial(b *testing.B) {
+	benchmarkCRC(b, 1)
+}
+
+func BenchmarkCRCParallel(b *testing.B) {
+	benchmarkCRC(b, 8)
+}
+
+func TestHardwareCRCAvailable(t *testing.T) {
+	available := HardwareCRCAvailable()
+	t.Logf("hardware CRC-32C available on %s: %v", runtime.GOARCH, available)
+	if runtime.GOARCH == "ppc64le" && !available {
+		t.Errorf("expect hardware CRC on ppc64le")
+	}
+	flag := map[string]string{"amd64": "sse4_2", "arm64": "crc32"}[runtime.GOARCH]
+	cpuinfo, err := os.ReadFile("/proc/cpuinfo")
+	if flag == "" || err != nil {
+		return
+	}
+	if expect := regexp.MustCompile(`\b` + flag + `\b`).Match(cpuinfo); available != expect {
+		t.Errorf("expect %v from %s in /proc/cpuinfo, actual %v", expect, flag, available)
+	}
+}
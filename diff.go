@@ -0,0 +1,35 @@
+package tfrecord
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Diff reads a and b in lockstep and returns index of the first record whose payload differs, byte for byte,
+// or -1 if they have the same records. When one stream is a prefix of the other, the index is the first record
+// only the longer one has, returned with an error wrapping ErrCountMismatch telling which one is longer. Read
+// errors of either stream are returned with -1, prefixed with "a: " or "b: ".
+func Diff(a, b io.Reader, checkDataCRC bool) (firstDiffIndex int, err error) {
+	ita := NewIterator(a, defaultBufSize, checkDataCRC)
+	itb := NewIterator(b, defaultBufSize, checkDataCRC)
+	for i := 0; ; i++ {
+		okA, okB := ita.Next(), itb.Next()
+		if err := ita.Err(); err != nil {
+			return -1, fmt.Errorf("a: %w", err)
+		}
+		if err := itb.Err(); err != nil {
+			return -1, fmt.Errorf("b: %w", err)
+		}
+		switch {
+		case !okA && !okB:
+			return -1, nil
+		case !okA:
+			return i, fmt.Errorf("a has %d records, b has more: %w", i, ErrCountMismatch)
+		case !okB:
+			return i, fmt.Errorf("b has %d records, a has more: %w", i, ErrCountMismatch)
+		case !bytes.Equal(ita.Value(), itb.Value()):
+			return i, nil
+		}
+	}
+}
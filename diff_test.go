@@ -0,0 +1,31 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	data := writeTestRecords(t, 10)
+	if i, err := Diff(bytes.NewReader(data), bytes.NewReader(data), true); i != -1 || err != nil {
+		t.Errorf("expect identical files, actual %d, %v", i, err)
+	}
+
+	changed := bytes.Clone(data)
+	changed[5*FrameSize(1)+HeaderSize] = 'x'
+	if i, err := Diff(bytes.NewReader(data), bytes.NewReader(changed), false); i != 5 || err != nil {
+		t.Errorf("expect difference at record 5, actual %d, %v", i, err)
+	}
+	if i, err := Diff(bytes.NewReader(data), bytes.NewReader(changed), true); i != -1 || !errors.Is(err, ErrChecksum) {
+		t.Errorf("expect checksum error, actual %d, %v", i, err)
+	}
+
+	short := writeTestRecords(t, 7)
+	if i, err := Diff(bytes.NewReader(short), bytes.NewReader(data), true); i != 7 || !errors.Is(err, ErrCountMismatch) {
+		t.Errorf("expect count mismatch at 7, actual %d, %v", i, err)
+	}
+	if i, err := Diff(bytes.NewReader(data), bytes.NewReader(nil), true); i != 0 || !errors.Is(err, ErrCountMismatch) {
+		t.Errorf("expect count mismatch at 0, actual %d, %v", i, err)
+	}
+}
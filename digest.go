@@ -0,0 +1,52 @@
+package tfrecord
+
+import (
+	"crypto/sha256"
+	"hash"
+	"io"
+)
+
+// DigestWriter writes TFRecords and computes SHA-256 of all framed bytes written, so the digest matches an
+// independent hash of the produced file, for naming or verifying files by content.
+type DigestWriter struct {
+	w      *Writer
+	h      hash.Hash
+	closed bool
+}
+
+// NewDigestWriter creates a DigestWriter writing to w.
+func NewDigestWriter(w io.Writer) *DigestWriter {
+	h := sha256.New()
+	return &DigestWriter{w: NewWriter(&hashingWriter{w: w, h: h}), h: h}
+}
+
+// Write writes a record.
+func (d *DigestWriter) Write(record []byte) (int, error) {
+	if d.closed {
+		return 0, ErrWriterClosed
+	}
+	return d.w.Write(record)
+}
+
+// Close finishes writing, the underlying writer is not closed. Sum is final after Close.
+func (d *DigestWriter) Close() error {
+	d.closed = true
+	return nil
+}
+
+// Sum returns SHA-256 digest of bytes written so far.
+func (d *DigestWriter) Sum() []byte {
+	return d.h.Sum(nil)
+}
+
+// hashingWriter writes to w and hashes the bytes w accepted.
+type hashingWriter struct {
+	w io.Writer
+	h hash.Hash
+}
+
+func (hw *hashingWriter) Write(p []byte) (int, error) {
+	n, err := hw.w.Write(p)
+	hw.h.Write(p[:n])
+	return n, err
+}
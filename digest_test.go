@@ -0,0 +1,26 @@
+package tfrecord
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestDigestWriter(t *testing.T) {
+	sw := &shortWriter{}
+	d := NewDigestWriter(sw)
+	for _, r := range []string{"Hello", "", "World!"} {
+		if _, err := d.Write([]byte(r)); err != nil {
+			t.Fatalf("write error %v", err)
+		}
+	}
+	if err := d.Close(); err != nil {
+		t.Fatalf("close error %v", err)
+	}
+	if _, err := d.Write([]byte("x")); err != ErrWriterClosed {
+		t.Errorf("expect ErrWriterClosed, actual %v", err)
+	}
+	if expect := sha256.Sum256(sw.buf.Bytes()); !bytes.Equal(d.Sum(), expect[:]) {
+		t.Errorf("expect digest %x, actual %x", expect, d.Sum())
+	}
+}
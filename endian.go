@@ -0,0 +1,28 @@
+package tfrecord
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrBigEndian is error returned by WithEndianDiagnostics when a header is valid once read as big-endian.
+var ErrBigEndian = errors.New("TFRecord header is big-endian, expect little-endian")
+
+// WithEndianDiagnostics makes the iterator check, when a length CRC fails, whether the header is valid when
+// read as big-endian, and report an error wrapping both ErrBigEndian and ErrChecksum if so, instead of a plain
+// checksum error. TFRecord spec doesn't define byte order, some exotic producers write big-endian. It's a
+// debugging aid, it only costs a CRC on length CRC failures.
+func WithEndianDiagnostics() Option {
+	return func(it *Iterator) {
+		it.endianDiag = true
+	}
+}
+
+// diagnoseHeader returns the error for a header failing length CRC.
+func (it *Iterator) diagnoseHeader(err error) error {
+	if it.endianDiag && binary.BigEndian.Uint32(it.header[LengthSize:]) == checksum(it.header[:LengthSize]) {
+		err = fmt.Errorf("record length %d as big-endian: %w: %w", binary.BigEndian.Uint64(it.header[:]), ErrBigEndian, err)
+	}
+	return err
+}
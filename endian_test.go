@@ -0,0 +1,44 @@
+package tfrecord
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// writeBigEndian frames record the way a big-endian producer would.
+func writeBigEndian(record []byte) []byte {
+	frame := make([]byte, HeaderSize+len(record)+FooterSize)
+	binary.BigEndian.PutUint64(frame, uint64(len(record)))
+	binary.BigEndian.PutUint32(frame[LengthSize:], checksum(frame[:LengthSize]))
+	copy(frame[HeaderSize:], record)
+	binary.BigEndian.PutUint32(frame[HeaderSize+len(record):], checksum(record))
+	return frame
+}
+
+func TestEndianDiagnostics(t *testing.T) {
+	data := append(writeTestRecords(t, 1), writeBigEndian([]byte("big"))...)
+	it := NewIterator(bytes.NewReader(data), 16, true, WithEndianDiagnostics())
+	for it.Next() {
+	}
+	if !errors.Is(it.Err(), ErrBigEndian) || !errors.Is(it.Err(), ErrChecksum) {
+		t.Errorf("expect ErrBigEndian and ErrChecksum, actual %v", it.Err())
+	}
+
+	it = NewIterator(bytes.NewReader(data), 16, true)
+	for it.Next() {
+	}
+	if errors.Is(it.Err(), ErrBigEndian) || !errors.Is(it.Err(), ErrChecksum) {
+		t.Errorf("expect plain ErrChecksum without diagnostics, actual %v", it.Err())
+	}
+
+	corrupt := writeTestRecords(t, 1)
+	corrupt[0]++
+	it = NewIterator(bytes.NewReader(corrupt), 16, true, WithEndianDiagnostics())
+	for it.Next() {
+	}
+	if errors.Is(it.Err(), ErrBigEndian) || !errors.Is(it.Err(), ErrChecksum) {
+		t.Errorf("expect plain ErrChecksum for corrupt header, actual %v", it.Err())
+	}
+}
@@ -0,0 +1,48 @@
+package tfrecord
+
+// FlatMapIterator expands every record of an Iterator into any number of sub-records.
+type FlatMapIterator struct {
+	it     *Iterator
+	expand func([]byte) ([][]byte, error)
+
+	pending [][]byte
+	value   []byte
+	err     error
+}
+
+// NewFlatMapIterator creates a FlatMapIterator, expand is called for each record of it and sub-records it returns
+// are iterated before advancing to next record. The record passed to expand is only valid during the call, so
+// sub-records aliasing it must be copied. An error from expand stops iteration and is reported by Err.
+func NewFlatMapIterator(it *Iterator, expand func([]byte) ([][]byte, error)) *FlatMapIterator {
+	return &FlatMapIterator{it: it, expand: expand}
+}
+
+// Next moves to next sub-record
+func (f *FlatMapIterator) Next() bool {
+	f.value = nil
+	for len(f.pending) == 0 {
+		if f.err != nil || !f.it.Next() {
+			return false
+		}
+		f.pending, f.err = f.expand(f.it.Value())
+		if f.err != nil {
+			f.pending = nil
+			return false
+		}
+	}
+	f.value, f.pending = f.pending[0], f.pending[1:]
+	return true
+}
+
+// Value returns current sub-record, as returned by expand
+func (f *FlatMapIterator) Value() []byte {
+	return f.value
+}
+
+// Err returns error from expand or the underlying iterator
+func (f *FlatMapIterator) Err() error {
+	if f.err != nil {
+		return f.err
+	}
+	return f.it.Err()
+}
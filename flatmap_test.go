@@ -0,0 +1,51 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFlatMapIterator(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	for _, r := range []string{"a,b", "", "c", "d,e,f"} {
+		if _, err := w.Write([]byte(r)); err != nil {
+			t.Fatalf("failed writing %v", err)
+		}
+	}
+	split := func(record []byte) ([][]byte, error) {
+		if len(record) == 0 {
+			return nil, nil
+		}
+		return bytes.Split(append([]byte(nil), record...), []byte(",")), nil
+	}
+
+	f := NewFlatMapIterator(NewIterator(bytes.NewReader(buf.Bytes()), 16, true), split)
+	var out []string
+	for f.Next() {
+		out = append(out, string(f.Value()))
+	}
+	if err := f.Err(); err != nil {
+		t.Fatalf("read error %v", err)
+	}
+	if actual := strings.Join(out, ""); actual != "abcdef" {
+		t.Errorf("expect abcdef, actual %s", actual)
+	}
+
+	errBad := errors.New("bad record")
+	f = NewFlatMapIterator(NewIterator(bytes.NewReader(buf.Bytes()), 16, true), func(record []byte) ([][]byte, error) {
+		if string(record) == "c" {
+			return nil, errBad
+		}
+		return split(record)
+	})
+	n := 0
+	for f.Next() {
+		n++
+	}
+	if n != 2 || f.Err() != errBad {
+		t.Errorf("expect stop after 2 sub-records with error, actual %d, err %v", n, f.Err())
+	}
+}
@@ -0,0 +1,104 @@
+package tfrecord
+
+import (
+	"io"
+)
+
+// WithAutoFlush makes the writer buffer framed records and write them to the destination once n bytes are
+// buffered, batching small writes while bounding memory to about n bytes, a record larger than n is written
+// straight through. Flush must be called after the last record to write the tail. With WithSync the
+// destination is synced after every flush, automatic ones included, so n also sets how often data is made
+// durable.
+func WithAutoFlush(n int) WriterOption {
+	return func(w *Writer) {
+		w.autoFlush = n
+	}
+}
+
+// WithSync makes Flush sync the destination after writing, when it has a Sync() error method like *os.File.
+// Without WithAutoFlush nothing is buffered and Flush only syncs.
+func WithSync() WriterOption {
+	return func(w *Writer) {
+		w.sync = true
+	}
+}
+
+// syncer is implemented by destinations that can commit written data to stable storage, like *os.File.
+type syncer interface {
+	Sync() error
+}
+
+func (w *Writer) initFlush() {
+	if w.autoFlush > 0 {
+		w.w = &flushWriter{dst: w.w, threshold: w.autoFlush, sync: w.sync}
+	}
+}
+
+// Flush writes buffered records to the destination and syncs it with WithSync.
+func (w *Writer) Flush() error {
+	fw, ok := w.w.(*flushWriter)
+	dst := w.w
+	if ok {
+		if err := fw.flush(); err != nil {
+			return err
+		}
+		dst = fw.dst
+	}
+	if s, ok := dst.(syncer); ok && w.sync {
+		return s.Sync()
+	}
+	return nil
+}
+
+// flushWriter buffers writes to dst, flushing when threshold bytes are buffered.
+type flushWriter struct {
+	dst       io.Writer
+	threshold int
+	buf       []byte
+	// sync syncs dst after automatic flushes.
+	sync bool
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	if len(p) >= f.threshold {
+		if err := f.flush(); err != nil {
+			return 0, err
+		}
+		if err := writeFull(f.dst, p); err != nil {
+			return 0, err
+		}
+		return len(p), f.syncDst()
+	}
+	f.buf = append(f.buf, p...)
+	if len(f.buf) >= f.threshold {
+		if err := f.autoFlush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (f *flushWriter) autoFlush() error {
+	if err := f.flush(); err != nil {
+		return err
+	}
+	return f.syncDst()
+}
+
+func (f *flushWriter) syncDst() error {
+	if s, ok := f.dst.(syncer); ok && f.sync {
+		return s.Sync()
+	}
+	return nil
+}
+
+func (f *flushWriter) flush() error {
+	if len(f.buf) == 0 {
+		return nil
+	}
+	if err := writeFull(f.dst, f.buf); err != nil {
+		return err
+	}
+	f.buf = f.buf[:0]
+	return nil
+}
@@ -0,0 +1,60 @@
+package tfrecord
+
+import (
+	"bytes"
+	"testing"
+)
+
+// syncBuffer is a bytes.Buffer recording sizes of writes and counting syncs.
+type syncBuffer struct {
+	bytes.Buffer
+	writes []int
+	syncs  int
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.writes = append(b.writes, len(p))
+	return b.Buffer.Write(p)
+}
+
+func (b *syncBuffer) Sync() error {
+	b.syncs++
+	return nil
+}
+
+func TestAutoFlush(t *testing.T) {
+	dst := &syncBuffer{}
+	w := NewWriter(dst, WithAutoFlush(100), WithSync())
+	for i := 0; i < 10; i++ {
+		w.Write([]byte{byte('0' + i)})
+	}
+	for _, n := range dst.writes {
+		if n < 100 || n >= 100+int(FrameSize(1)) {
+			t.Errorf("expect writes of about 100 bytes, actual %d", n)
+		}
+	}
+	if dst.Len() == 0 || dst.Len() == 10*int(FrameSize(1)) {
+		t.Errorf("expect tail buffered until Flush, actual %d bytes written", dst.Len())
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("flush error %v", err)
+	}
+	if !bytes.Equal(dst.Bytes(), writeTestRecords(t, 10)) {
+		t.Errorf("expect all records after Flush")
+	}
+	if dst.syncs != len(dst.writes) {
+		t.Errorf("expect a sync per flush, actual %d syncs for %d writes", dst.syncs, len(dst.writes))
+	}
+
+	dst = &syncBuffer{}
+	w = NewWriter(dst, WithAutoFlush(100))
+	w.Write([]byte("x"))
+	w.Write(make([]byte, 200))
+	if len(dst.writes) != 2 || dst.writes[0] != int(FrameSize(1)) || dst.writes[1] != int(FrameSize(200)) {
+		t.Errorf("expect large record written through, actual writes %v", dst.writes)
+	}
+	w.Flush()
+	if dst.syncs != 0 || dst.Len() != int(FrameSize(1)+FrameSize(200)) {
+		t.Errorf("expect all written without sync, actual %d bytes, %d syncs", dst.Len(), dst.syncs)
+	}
+}
@@ -0,0 +1,91 @@
+package tfrecord
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// errNoData is returned by followReader at end of data, a transient error the iterator resumes from.
+var errNoData = errors.New("no more data yet")
+
+// FollowIterator iterates records of a file still being appended to, like tail -f. At end of data it waits for
+// more instead of stopping, including in the middle of a record: only complete records are returned, a partial
+// record at the end is kept until the rest is appended.
+type FollowIterator struct {
+	ctx      context.Context
+	interval time.Duration
+	it       *Iterator
+	err      error
+}
+
+// NewFollowIterator creates a FollowIterator reading r, usually an *os.File, whose reads return more data
+// after io.EOF once more is appended. When no complete record is available r is polled every interval until ctx
+// is done. bufSize, checkDataCRC and opts are used to create the underlying Iterator.
+func NewFollowIterator(ctx context.Context, r io.Reader, interval time.Duration, bufSize int64, checkDataCRC bool, opts ...Option) *FollowIterator {
+	opts = append(opts[:len(opts):len(opts)], WithResumableReads(), WithRetryableError(func(err error) bool {
+		return errors.Is(err, errNoData)
+	}))
+	return &FollowIterator{
+		ctx:      ctx,
+		interval: interval,
+		it:       NewIterator(&followReader{r: r}, bufSize, checkDataCRC, opts...),
+	}
+}
+
+// Next moves to next record, blocking until a complete one is available. It returns false when ctx is done,
+// Err then returns ctx.Err(), or on error.
+func (f *FollowIterator) Next() bool {
+	for f.err == nil {
+		if err := f.ctx.Err(); err != nil {
+			f.err = err
+			break
+		}
+		if f.it.Next() {
+			return true
+		}
+		if err := f.it.Err(); !errors.Is(err, errNoData) {
+			f.err = err
+			break
+		}
+		select {
+		case <-f.ctx.Done():
+			f.err = f.ctx.Err()
+		case <-time.After(f.interval):
+		}
+	}
+	return false
+}
+
+// Value returns the current record, valid until next call to Next
+func (f *FollowIterator) Value() []byte {
+	return f.it.Value()
+}
+
+// State returns checkpoint of the underlying iterator, only complete records count. To follow again later,
+// seek the file to its Offset before creating a new FollowIterator.
+func (f *FollowIterator) State() IteratorState {
+	return f.it.State()
+}
+
+// Err returns error stopping iteration, ctx.Err() when stopped by ctx.
+func (f *FollowIterator) Err() error {
+	return f.err
+}
+
+// followReader reports io.EOF of r as errNoData.
+type followReader struct {
+	r io.Reader
+}
+
+func (r *followReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err == io.EOF {
+		if n > 0 {
+			return n, nil
+		}
+		return 0, errNoData
+	}
+	return n, err
+}
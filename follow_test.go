@@ -0,0 +1,76 @@
+package tfrecord
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestFollowIterator(t *testing.T) {
+	data := writeTestRecords(t, 5)
+	path := filepath.Join(t.TempDir(), "growing.tfrecord")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	it := NewFollowIterator(ctx, r, time.Millisecond, 16, true)
+	// Append frames in pieces cutting through headers, payloads and footers.
+	go func() {
+		for i := 0; i < len(data); i += 5 {
+			f.Write(data[i:min(i+5, len(data))])
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	for i := 0; i < 5; i++ {
+		if !it.Next() {
+			t.Fatalf("expect record %d, err %v", i, it.Err())
+		}
+		if string(it.Value()) != strconv.Itoa(i) {
+			t.Errorf("expect record %d, actual %q", i, it.Value())
+		}
+	}
+
+	done := make(chan bool)
+	go func() {
+		done <- it.Next()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if <-done || !errors.Is(it.Err(), context.Canceled) {
+		t.Errorf("expect cancellation, err %v", it.Err())
+	}
+	if s := it.State(); s.Offset != int64(len(data)) || s.Ordinal != 5 {
+		t.Errorf("unexpected state %+v", s)
+	}
+}
+
+func TestFollowIteratorCorrupt(t *testing.T) {
+	data := writeTestRecords(t, 3)
+	data[HeaderSize]++
+	path := filepath.Join(t.TempDir(), "corrupt.tfrecord")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	it := NewFollowIterator(context.Background(), r, time.Millisecond, 16, true)
+	if it.Next() || !errors.Is(it.Err(), ErrChecksum) {
+		t.Errorf("expect checksum error, actual %v", it.Err())
+	}
+}
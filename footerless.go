@@ -0,0 +1,34 @@
+package tfrecord
+
+// WithoutDataCRC makes the writer omit the footer, the data CRC, of each record to save 4 bytes per record.
+// Such files are NOT TFRecords: TensorFlow and any standard reader fail on them, typically with a length CRC
+// error at the second record since they expect a footer there. Only read them with WithFooterless, and don't
+// mix them with standard files.
+func WithoutDataCRC() WriterOption {
+	return func(w *Writer) {
+		w.noFooter = true
+	}
+}
+
+func (w *Writer) footerLen() int {
+	if w.noFooter || w.raw {
+		return 0
+	}
+	return FooterSize
+}
+
+// WithFooterless makes the iterator read files written with WithoutDataCRC, records have no footer so data
+// isn't checked regardless of checkDataCRC, length CRC still is. Standard files read this way fail with a
+// length CRC error at the second record.
+func WithFooterless() Option {
+	return func(it *Iterator) {
+		it.noFooter = true
+	}
+}
+
+func (it *Iterator) footerLen() int {
+	if it.noFooter || it.raw {
+		return 0
+	}
+	return FooterSize
+}
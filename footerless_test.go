@@ -0,0 +1,53 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFooterless(t *testing.T) {
+	records := []string{"0", "", strings.Repeat("x", singleWriteMax+1), "3"}
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf, WithoutDataCRC())
+	for _, r := range records {
+		w.Write([]byte(r))
+	}
+	if expect := 4*HeaderSize + 2 + singleWriteMax + 1; buf.Len() != expect {
+		t.Errorf("expect %d bytes, actual %d", expect, buf.Len())
+	}
+
+	it := NewIterator(bytes.NewReader(buf.Bytes()), 16, true, WithFooterless())
+	for i, r := range records {
+		if !it.Next() || string(it.Value()) != r {
+			t.Fatalf("expect record %d, actual %v", i, it.Err())
+		}
+	}
+	if it.Next() || it.Err() != nil {
+		t.Errorf("expect clean end, actual %v", it.Err())
+	}
+	if s := it.State(); s.Offset != int64(buf.Len()) {
+		t.Errorf("expect offset %d, actual %d", buf.Len(), s.Offset)
+	}
+
+	// mixing modes fails loudly.
+	it = NewIterator(bytes.NewReader(buf.Bytes()), 16, true)
+	for it.Next() {
+	}
+	if !errors.Is(it.Err(), ErrChecksum) {
+		t.Errorf("expect ErrChecksum reading footerless file as standard, actual %v", it.Err())
+	}
+	it = NewIterator(bytes.NewReader(writeTestRecords(t, 3)), 16, true, WithFooterless())
+	n := 0
+	for it.Next() {
+		if string(it.Value()) != strconv.Itoa(n) {
+			break
+		}
+		n++
+	}
+	if n != 1 || !errors.Is(it.Err(), ErrChecksum) {
+		t.Errorf("expect ErrChecksum after first record, actual %d, %v", n, it.Err())
+	}
+}
@@ -0,0 +1,77 @@
+package tfrecord
+
+import (
+	"io"
+	"time"
+)
+
+// hedgedReaderAt is io.ReaderAt reading from replicas with hedging.
+type hedgedReaderAt struct {
+	backends []io.ReaderAt
+	delay    time.Duration
+}
+
+// NewHedgedReaderAt returns an io.ReaderAt over replicas of the same content, to cut tail latency of random
+// access, for example through IndexedReader. Each ReadAt goes to the first backend, if it hasn't responded
+// after delay the same read is sent to the next backend and so on, the first successful response wins. Reads
+// can't be interrupted through io.ReaderAt, slower ones run to completion in background and their results
+// are dropped. Replicas are assumed identical, it's not checked: when they disagree result is from whichever
+// responded first, CRC checks of IndexedReader still catch a corrupt replica. If all backends fail, the
+// error of the first failing one is returned. It panics if backends is empty.
+func NewHedgedReaderAt(backends []io.ReaderAt, delay time.Duration) io.ReaderAt {
+	if len(backends) == 0 {
+		panic("tfrecord: NewHedgedReaderAt needs at least one backend")
+	}
+	return &hedgedReaderAt{backends: backends, delay: delay}
+}
+
+type hedgedResult struct {
+	buf []byte
+	n   int
+	err error
+}
+
+func (h *hedgedReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	// buffered for all backends so slower ones never block after ReadAt returns.
+	results := make(chan hedgedResult, len(h.backends))
+	start := func(b io.ReaderAt) {
+		go func() {
+			buf := make([]byte, len(p))
+			n, err := b.ReadAt(buf, off)
+			results <- hedgedResult{buf, n, err}
+		}()
+	}
+	start(h.backends[0])
+	started, done := 1, 0
+	var firstErr *hedgedResult
+	timer := time.NewTimer(h.delay)
+	defer timer.Stop()
+	for done < started || started < len(h.backends) {
+		var hedge <-chan time.Time
+		if started < len(h.backends) {
+			hedge = timer.C
+		}
+		select {
+		case r := <-results:
+			done++
+			// a full read at EOF is a success too.
+			if r.err == nil || (r.err == io.EOF && r.n == len(p)) {
+				return copy(p, r.buf[:r.n]), r.err
+			}
+			if firstErr == nil {
+				firstErr = &r
+			}
+			if done == started && started < len(h.backends) {
+				// all running reads failed, hedge right away.
+				start(h.backends[started])
+				started++
+				timer.Reset(h.delay)
+			}
+		case <-hedge:
+			start(h.backends[started])
+			started++
+			timer.Reset(h.delay)
+		}
+	}
+	return copy(p, firstErr.buf[:firstErr.n]), firstErr.err
+}
@@ -0,0 +1,68 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowReaderAt delays ReadAt and counts calls.
+type slowReaderAt struct {
+	r     io.ReaderAt
+	delay time.Duration
+	err   error
+	calls atomic.Int32
+}
+
+func (s *slowReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	s.calls.Add(1)
+	time.Sleep(s.delay)
+	if s.err != nil {
+		return 0, s.err
+	}
+	return s.r.ReadAt(p, off)
+}
+
+func TestHedgedReaderAt(t *testing.T) {
+	data := writeSizedRecords(t, 3, 10)
+	index, _ := BuildIndex(bytes.NewReader(data))
+	slow := &slowReaderAt{r: bytes.NewReader(data), delay: time.Second}
+	fast := &slowReaderAt{r: bytes.NewReader(data)}
+	r := NewIndexedReader(NewHedgedReaderAt([]io.ReaderAt{slow, fast}, 10*time.Millisecond), true)
+	start := time.Now()
+	if record, err := r.ReadAt(index[1]); err != nil || len(record) != 10 {
+		t.Fatalf("expect record of 10 bytes, actual %d, %v", len(record), err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expect hedged read to skip slow backend, took %v", elapsed)
+	}
+
+	// no hedging when the first backend is quick.
+	fast.calls.Store(0)
+	quick := &slowReaderAt{r: bytes.NewReader(data)}
+	NewHedgedReaderAt([]io.ReaderAt{quick, fast}, time.Second).ReadAt(make([]byte, 4), 0)
+	if fast.calls.Load() != 0 {
+		t.Errorf("expect no hedged read")
+	}
+
+	errFailed := errors.New("failed")
+	failing := &slowReaderAt{err: errFailed}
+	if _, err := NewHedgedReaderAt([]io.ReaderAt{failing, fast}, time.Second).ReadAt(make([]byte, 4), 0); err != nil {
+		t.Errorf("expect fallback to second backend, actual %v", err)
+	}
+	if _, err := NewHedgedReaderAt([]io.ReaderAt{failing, failing}, 0).ReadAt(make([]byte, 4), 0); err != errFailed {
+		t.Errorf("expect error of failing backends, actual %v", err)
+	}
+}
+
+func TestHedgedReaderAtNoBackend(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expect panic without backends")
+		}
+	}()
+	NewHedgedReaderAt(nil, time.Millisecond)
+}
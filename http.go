@@ -0,0 +1,90 @@
+package tfrecord
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ErrRangeNotSupported is error returned by the ReaderAt of NewHTTPReaderAt when server ignores range requests.
+var ErrRangeNotSupported = errors.New("HTTP server doesn't support range requests")
+
+// httpMinFetch is the minimal number of bytes fetched per range request, small reads are served from the last
+// fetched block so adjacent reads like header then payload don't each cost a request.
+const httpMinFetch = 64 * 1024
+
+// NewHTTPReaderAt creates an io.ReaderAt reading url through HTTP range requests with client, http.DefaultClient
+// is used when client is nil. Small adjacent reads are coalesced into one request. Reads fail with
+// ErrRangeNotSupported if server responds to a range request with the full content.
+func NewHTTPReaderAt(url string, client *http.Client) io.ReaderAt {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpReaderAt{url: url, client: client}
+}
+
+type httpReaderAt struct {
+	url    string
+	client *http.Client
+
+	mu         sync.Mutex
+	blockStart int64
+	block      []byte
+}
+
+func (r *httpReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("read %s at negative offset %d", r.url, off)
+	}
+	r.mu.Lock()
+	if off >= r.blockStart && off+int64(len(p)) <= r.blockStart+int64(len(r.block)) {
+		n := copy(p, r.block[off-r.blockStart:])
+		r.mu.Unlock()
+		return n, nil
+	}
+	r.mu.Unlock()
+
+	size := len(p)
+	if size < httpMinFetch {
+		size = httpMinFetch
+	}
+	data, err := r.fetch(off, size)
+	if err != nil {
+		return 0, err
+	}
+	if len(p) < httpMinFetch {
+		r.mu.Lock()
+		r.blockStart, r.block = off, data
+		r.mu.Unlock()
+	}
+	n := copy(p, data)
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (r *httpReaderAt) fetch(off int64, size int) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, off+int64(size)-1))
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return io.ReadAll(io.LimitReader(resp.Body, int64(size)))
+	case http.StatusRequestedRangeNotSatisfiable:
+		return nil, io.EOF
+	case http.StatusOK:
+		return nil, fmt.Errorf("GET %s: %w", r.url, ErrRangeNotSupported)
+	default:
+		return nil, fmt.Errorf("GET %s: unexpected status %s", r.url, resp.Status)
+	}
+}
@@ -0,0 +1,67 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPReaderAt(t *testing.T) {
+	data := make([]byte, 3*httpMinFetch)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.ServeContent(w, req, "data", time.Time{}, bytes.NewReader(data))
+	}))
+	defer server.Close()
+
+	r := NewHTTPReaderAt(server.URL, nil)
+	p := make([]byte, 12)
+	for off := int64(0); off < 100; off += int64(len(p)) {
+		if n, err := r.ReadAt(p, off); err != nil || n != len(p) {
+			t.Fatalf("failed reading at %d, n %d, err %v", off, n, err)
+		}
+		if !bytes.Equal(p, data[off:off+int64(len(p))]) {
+			t.Fatalf("unmatched content at %d", off)
+		}
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("expect adjacent small reads coalesced into 1 request, actual %d", n)
+	}
+
+	large := make([]byte, 2*httpMinFetch)
+	if n, err := r.ReadAt(large, httpMinFetch); err != nil || n != len(large) {
+		t.Fatalf("failed large read, n %d, err %v", n, err)
+	}
+	if !bytes.Equal(large, data[httpMinFetch:]) {
+		t.Errorf("unmatched large read content")
+	}
+
+	n, err := r.ReadAt(p, int64(len(data))-4)
+	if n != 4 || err != io.EOF {
+		t.Errorf("expect short read with EOF at end, actual n %d, err %v", n, err)
+	}
+	if _, err := r.ReadAt(p, int64(len(data))+10); err != io.EOF {
+		t.Errorf("expect EOF beyond end, actual %v", err)
+	}
+}
+
+func TestHTTPReaderAtNoRange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("no range support"))
+	}))
+	defer server.Close()
+
+	r := NewHTTPReaderAt(server.URL, server.Client())
+	if _, err := r.ReadAt(make([]byte, 4), 2); !errors.Is(err, ErrRangeNotSupported) {
+		t.Errorf("expect ErrRangeNotSupported, actual %v", err)
+	}
+}
@@ -0,0 +1,10 @@
+package tfrecord
+
+// cpuid is implemented in hwcrc_amd64.s.
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+// hardwareCRC checks SSE4.2, which has the CRC32 instruction hash/crc32 uses.
+func hardwareCRC() bool {
+	_, _, ecx, _ := cpuid(1, 0)
+	return ecx&(1<<20) != 0
+}
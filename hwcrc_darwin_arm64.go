@@ -0,0 +1,6 @@
+package tfrecord
+
+// hardwareCRC is always true, every Apple silicon CPU has CRC32 instructions.
+func hardwareCRC() bool {
+	return true
+}
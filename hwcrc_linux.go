@@ -0,0 +1,32 @@
+//go:build linux && (arm64 || loong64 || s390x)
+
+package tfrecord
+
+import (
+	"encoding/binary"
+	"os"
+	"runtime"
+)
+
+const atHWCap = 16
+
+// hwcapCRC is the AT_HWCAP bit of the CPU feature hash/crc32 needs for hardware CRC-32C.
+var hwcapCRC = map[string]uint64{
+	"arm64":   1 << 7,  // HWCAP_CRC32
+	"loong64": 1 << 6,  // HWCAP_LOONGARCH_CRC32
+	"s390x":   1 << 11, // HWCAP_S390_VX
+}
+
+// hardwareCRC reads CPU features from the auxiliary vector, like hash/crc32 does through the runtime.
+func hardwareCRC() bool {
+	auxv, err := os.ReadFile("/proc/self/auxv")
+	if err != nil {
+		return false
+	}
+	for ; len(auxv) >= 16; auxv = auxv[16:] {
+		if binary.NativeEndian.Uint64(auxv) == atHWCap {
+			return binary.NativeEndian.Uint64(auxv[8:])&hwcapCRC[runtime.GOARCH] != 0
+		}
+	}
+	return false
+}
@@ -0,0 +1,7 @@
+//go:build !amd64 && !ppc64le && !(linux && (arm64 || loong64 || s390x)) && !(darwin && arm64)
+
+package tfrecord
+
+func hardwareCRC() bool {
+	return false
+}
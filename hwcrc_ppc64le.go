@@ -0,0 +1,7 @@
+package tfrecord
+
+// hardwareCRC is always true, POWER8, the minimum Go supports, has vector polynomial instructions hash/crc32
+// uses.
+func hardwareCRC() bool {
+	return true
+}
@@ -0,0 +1,261 @@
+package tfrecord
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// RecordLocation locates a record in a TFRecord file.
+type RecordLocation struct {
+	// Offset is the position of the record's header in the file.
+	Offset int64
+	// Length is the payload length of the record.
+	Length uint64
+}
+
+// OverwriteAt replaces the record at loc with record in place, both CRCs are recomputed. record must have
+// the same length as the existing one, otherwise an error is returned and nothing is written.
+func OverwriteAt(w io.WriterAt, loc RecordLocation, record []byte) error {
+	if uint64(len(record)) != loc.Length {
+		return fmt.Errorf("overwrite record of length %d at offset %d with length %d, sizes must match",
+			loc.Length, loc.Offset, len(record))
+	}
+	frame := make([]byte, HeaderSize+len(record)+FooterSize)
+	putHeader(frame[:HeaderSize], loc.Length)
+	copy(frame[HeaderSize:], record)
+	putFooter(frame[HeaderSize+len(record):], record)
+	_, err := w.WriteAt(frame, loc.Offset)
+	return err
+}
+
+// IndexingWriter writes TFRecords and records location of every record written.
+type IndexingWriter struct {
+	w      *Writer
+	offset int64
+	index  []RecordLocation
+}
+
+// NewIndexingWriter creates an IndexingWriter on top of w, offset is the position in file where w starts
+// writing, locations in index are relative to that file.
+func NewIndexingWriter(w io.Writer, offset int64) *IndexingWriter {
+	return &IndexingWriter{w: NewWriter(w), offset: offset}
+}
+
+// Write implements io.Writer, writes record and adds its location to index.
+func (w *IndexingWriter) Write(record []byte) (int, error) {
+	n, err := w.w.Write(record)
+	if err != nil {
+		return n, err
+	}
+	w.index = append(w.index, RecordLocation{Offset: w.offset, Length: uint64(len(record))})
+	w.offset += FrameSize(len(record))
+	return n, nil
+}
+
+// Offset returns file position where next record will be written.
+func (w *IndexingWriter) Offset() int64 {
+	return w.offset
+}
+
+// Index returns locations of records written so far.
+func (w *IndexingWriter) Index() []RecordLocation {
+	return w.index
+}
+
+// AppendTo prepares appending records to existing TFRecord file f. It checks records in f end exactly at end
+// of file and the last record passes CRC check, so new records never follow a truncated tail. Returned
+// IndexingWriter writes at end of f, its index only covers appended records, with offsets in f.
+func AppendTo(f *os.File) (*IndexingWriter, error) {
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTail(f, size); err != nil {
+		return nil, err
+	}
+	return NewIndexingWriter(f, size), nil
+}
+
+// checkTail walks record headers in r and verifies the last record is complete and intact.
+func checkTail(r io.ReaderAt, size int64) error {
+	var (
+		last   RecordLocation
+		header [HeaderSize]byte
+	)
+	if size == 0 {
+		return nil
+	}
+	for offset := int64(0); offset < size; {
+		if size-offset < HeaderSize {
+			return &RecordError{Offset: offset, Err: ErrTruncated}
+		}
+		if _, err := r.ReadAt(header[:], offset); err != nil {
+			return err
+		}
+		length, err := parseHeader(header[:])
+		if err != nil {
+			return &RecordError{
+				Offset:      offset,
+				StoredCRC:   binary.LittleEndian.Uint32(header[LengthSize:]),
+				ComputedCRC: checksum(header[:LengthSize]),
+				Err:         err,
+			}
+		}
+		if remain := size - offset - HeaderSize - FooterSize; remain < 0 || length > uint64(remain) {
+			return &RecordError{Offset: offset, Err: ErrTruncated}
+		}
+		if err := checkRecordLen(length, offset); err != nil {
+			return err
+		}
+		last = RecordLocation{Offset: offset, Length: length}
+		offset += HeaderSize + int64(length) + FooterSize
+	}
+	frame := make([]byte, HeaderSize+last.Length+FooterSize)
+	if _, err := r.ReadAt(frame, last.Offset); err != nil {
+		return err
+	}
+	stored := binary.LittleEndian.Uint32(frame[HeaderSize+last.Length:])
+	if crc := checksum(frame[HeaderSize : HeaderSize+last.Length]); crc != stored {
+		return &RecordError{Offset: last.Offset, StoredCRC: stored, ComputedCRC: crc, Err: ErrChecksum}
+	}
+	return nil
+}
+
+// BuildIndex scans r and returns locations of all records, offsets are relative to current position of r. Only
+// record headers are read, when r is an io.Seeker payloads are skipped by seeking.
+func BuildIndex(r io.Reader) ([]RecordLocation, error) {
+	var (
+		index  []RecordLocation
+		offset int64
+	)
+	err := scanHeaders(r, func(length uint64) error {
+		index = append(index, RecordLocation{Offset: offset, Length: length})
+		offset += HeaderSize + int64(length) + FooterSize
+		return nil
+	})
+	return index, err
+}
+
+// IndexedReader reads records at known locations from an io.ReaderAt.
+type IndexedReader struct {
+	r            io.ReaderAt
+	checkDataCRC bool
+}
+
+// NewIndexedReader creates an IndexedReader, when checkDataCRC is true CRC of record data is checked.
+// Location of records usually comes from BuildIndex or IndexingWriter.
+func NewIndexedReader(r io.ReaderAt, checkDataCRC bool) *IndexedReader {
+	return &IndexedReader{r: r, checkDataCRC: checkDataCRC}
+}
+
+// ReadAt reads record at loc, the returned record is newly allocated.
+func (r *IndexedReader) ReadAt(loc RecordLocation) ([]byte, error) {
+	if err := checkRecordLen(loc.Length, loc.Offset); err != nil {
+		return nil, err
+	}
+	frame := make([]byte, HeaderSize+loc.Length+FooterSize)
+	if n, err := r.r.ReadAt(frame, loc.Offset); err != nil && !(err == io.EOF && n == len(frame)) {
+		if err == io.EOF {
+			return nil, &RecordError{Offset: loc.Offset, Err: ErrTruncated}
+		}
+		return nil, err
+	}
+	length, err := parseHeader(frame[:HeaderSize])
+	if err != nil {
+		return nil, &RecordError{
+			Offset:      loc.Offset,
+			StoredCRC:   binary.LittleEndian.Uint32(frame[LengthSize:HeaderSize]),
+			ComputedCRC: checksum(frame[:LengthSize]),
+			Err:         err,
+		}
+	}
+	if length != loc.Length {
+		return nil, fmt.Errorf("record at offset %d has length %d, expect %d", loc.Offset, length, loc.Length)
+	}
+	record := frame[HeaderSize : HeaderSize+length]
+	if r.checkDataCRC {
+		stored := binary.LittleEndian.Uint32(frame[HeaderSize+length:])
+		if crc := checksum(record); crc != stored {
+			return nil, &RecordError{Offset: loc.Offset, StoredCRC: stored, ComputedCRC: crc, Err: ErrChecksum}
+		}
+	}
+	return record, nil
+}
+
+// readSeekerAt adapts an io.ReadSeeker to io.ReaderAt with offsets relative to base, it's not safe for
+// concurrent use.
+type readSeekerAt struct {
+	r    io.ReadSeeker
+	base int64
+}
+
+// newReaderAt returns r as an io.ReaderAt with offsets relative to current position of r.
+func newReaderAt(r io.ReadSeeker) (io.ReaderAt, error) {
+	base, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if ra, ok := r.(io.ReaderAt); ok {
+		if base == 0 {
+			return ra, nil
+		}
+		return io.NewSectionReader(ra, base, maxInt64-base), nil
+	}
+	return &readSeekerAt{r: r, base: base}, nil
+}
+
+func (r *readSeekerAt) ReadAt(p []byte, off int64) (int, error) {
+	if _, err := r.r.Seek(r.base+off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(r.r, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// RawFrameReader returns a reader streaming the framed bytes, header and footer included, of records locs[i]
+// for i in indices, in that order. Bytes are copied verbatim from r, so original CRCs are preserved exactly.
+// An index out of range of locs makes the reader fail at that point, so does a frame cut short by end of r,
+// with ErrTruncated.
+func RawFrameReader(r io.ReaderAt, locs []RecordLocation, indices []int) io.Reader {
+	readers := make([]io.Reader, 0, len(indices))
+	for _, i := range indices {
+		if i < 0 || i >= len(locs) {
+			readers = append(readers, &errReader{fmt.Errorf("record index %d out of range [0, %d)", i, len(locs))})
+			break
+		}
+		loc := locs[i]
+		size := HeaderSize + int64(loc.Length) + FooterSize
+		readers = append(readers, &frameSection{r: io.NewSectionReader(r, loc.Offset, size), loc: loc, rest: size})
+	}
+	return io.MultiReader(readers...)
+}
+
+// frameSection reads the frame at loc, failing with ErrTruncated if r ends before the whole frame is read.
+type frameSection struct {
+	r    io.Reader
+	loc  RecordLocation
+	rest int64
+}
+
+func (f *frameSection) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	f.rest -= int64(n)
+	if err == io.EOF && f.rest > 0 {
+		err = &RecordError{Offset: f.loc.Offset, Err: ErrTruncated}
+	}
+	return n, err
+}
+
+// errReader is an io.Reader always failing with err.
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
@@ -0,0 +1,177 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOverwriteAt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.tfrecord")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed creating file %v", err)
+	}
+	defer f.Close()
+	w := NewWriter(f)
+	for _, r := range []string{"aaa", "bbb", "ccc"} {
+		if _, err := w.Write([]byte(r)); err != nil {
+			t.Fatalf("failed writing %v", err)
+		}
+	}
+
+	loc := RecordLocation{Offset: HeaderSize + 3 + FooterSize, Length: 3}
+	if err := OverwriteAt(f, loc, []byte("bbbb")); err == nil {
+		t.Errorf("expect error overwriting with different size")
+	}
+	if err := OverwriteAt(f, loc, []byte("xyz")); err != nil {
+		t.Fatalf("failed overwriting %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed reading file %v", err)
+	}
+	it := NewIterator(bytes.NewReader(data), 16, true)
+	out := ""
+	for it.Next() {
+		out += string(it.Value())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("read error %v", err)
+	}
+	if out != "aaaxyzccc" {
+		t.Errorf("unmatched content, expect aaaxyzccc, actual %s", out)
+	}
+}
+
+func TestAppendTo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.tfrecord")
+	if err := os.WriteFile(path, writeTestRecords(t, 3), 0644); err != nil {
+		t.Fatalf("failed writing file %v", err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("failed opening file %v", err)
+	}
+	w, err := AppendTo(f)
+	if err != nil {
+		t.Fatalf("failed appending %v", err)
+	}
+	start := w.Offset()
+	for _, r := range []string{"3", "4"} {
+		if _, err := w.Write([]byte(r)); err != nil {
+			t.Fatalf("failed writing %v", err)
+		}
+	}
+	f.Close()
+	expect := []RecordLocation{
+		{Offset: start, Length: 1},
+		{Offset: start + HeaderSize + 1 + FooterSize, Length: 1},
+	}
+	index := w.Index()
+	if len(index) != len(expect) || index[0] != expect[0] || index[1] != expect[1] {
+		t.Errorf("unmatched index, expect %v, actual %v", expect, index)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed reading file %v", err)
+	}
+	it := NewIterator(bytes.NewReader(data), 16, true)
+	out := ""
+	for it.Next() {
+		out += string(it.Value())
+	}
+	if err := it.Err(); err != nil || out != "01234" {
+		t.Errorf("unexpected content %s, err %v", out, err)
+	}
+}
+
+func TestAppendToCorrupt(t *testing.T) {
+	data := writeTestRecords(t, 3)
+	dir := t.TempDir()
+	for name, c := range map[string]struct {
+		data   []byte
+		expect error
+	}{
+		"truncated": {data[:len(data)-1], ErrTruncated},
+		"partial":   {data[:len(data)-6], ErrTruncated},
+		"checksum":  {append(append([]byte(nil), data[:len(data)-1]...), data[len(data)-1]+1), ErrChecksum},
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, c.data, 0644); err != nil {
+			t.Fatalf("failed writing file %v", err)
+		}
+		f, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			t.Fatalf("failed opening file %v", err)
+		}
+		if _, err := AppendTo(f); !errors.Is(err, c.expect) {
+			t.Errorf("%s: expect %v, actual %v", name, c.expect, err)
+		}
+		f.Close()
+	}
+}
+
+func TestIndexedReader(t *testing.T) {
+	data := writeSizedRecords(t, 3, 0, 10)
+	index, err := BuildIndex(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed building index %v", err)
+	}
+	expect := []RecordLocation{{0, 3}, {19, 0}, {35, 10}}
+	if len(index) != len(expect) {
+		t.Fatalf("expect index %v, actual %v", expect, index)
+	}
+	r := NewIndexedReader(bytes.NewReader(data), true)
+	for i := len(index) - 1; i >= 0; i-- {
+		if index[i] != expect[i] {
+			t.Errorf("expect location %v, actual %v", expect[i], index[i])
+		}
+		record, err := r.ReadAt(index[i])
+		if err != nil || len(record) != int(index[i].Length) {
+			t.Errorf("failed reading record %d, %v", i, err)
+		}
+	}
+
+	data[35+HeaderSize]++
+	if _, err := r.ReadAt(index[2]); !errors.Is(err, ErrChecksum) {
+		t.Errorf("expect ErrChecksum, actual %v", err)
+	}
+	if _, err := r.ReadAt(RecordLocation{Offset: 35, Length: 11}); err == nil {
+		t.Errorf("expect error reading with wrong length")
+	}
+	if _, err := NewIndexedReader(bytes.NewReader(data[:40]), true).ReadAt(index[2]); !errors.Is(err, ErrTruncated) {
+		t.Errorf("expect ErrTruncated, actual %v", err)
+	}
+}
+
+func TestRawFrameReader(t *testing.T) {
+	data := writeSizedRecords(t, 3, 0, 10)
+	// corrupt data CRC of last record, it must be preserved as is.
+	data[len(data)-1]++
+	index, err := BuildIndex(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed building index %v", err)
+	}
+	out := &bytes.Buffer{}
+	if _, err := io.Copy(out, RawFrameReader(bytes.NewReader(data), index, []int{2, 0})); err != nil {
+		t.Fatalf("copy error %v", err)
+	}
+	expect := append(append([]byte{}, data[35:]...), data[:19]...)
+	if !bytes.Equal(out.Bytes(), expect) {
+		t.Errorf("unexpected frames % x", out.Bytes())
+	}
+	if _, err := io.ReadAll(RawFrameReader(bytes.NewReader(data), index, []int{0, 3})); err == nil {
+		t.Errorf("expect error for index out of range")
+	}
+	var re *RecordError
+	if _, err := io.ReadAll(RawFrameReader(bytes.NewReader(data[:len(data)-3]), index, []int{0, 2})); !errors.As(err, &re) ||
+		re.Err != ErrTruncated || re.Offset != index[2].Offset {
+		t.Errorf("expect ErrTruncated at last record, actual %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package tfrecord
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// WeightedSource is a source of WeightedInterleaveIterator.
+type WeightedSource struct {
+	R      io.Reader
+	Weight float64
+}
+
+// WeightedInterleaveIterator interleaves records from several sources, picking the source of each record at
+// random in proportion to source weights, like tf.data.Dataset.sample_from_datasets. Exhausted sources are
+// skipped and weights of remaining ones renormalized, iteration ends when all sources are exhausted.
+type WeightedInterleaveIterator struct {
+	its     []*Iterator
+	weights []float64
+	rng     *rand.Rand
+
+	total  float64
+	source int
+	value  []byte
+	err    error
+}
+
+// NewWeightedInterleaveIterator creates a WeightedInterleaveIterator, each source is read by an Iterator
+// created with bufSize and checkDataCRC. rng drives source selection, sources with non-positive weight are
+// never read.
+func NewWeightedInterleaveIterator(sources []WeightedSource, bufSize int64, checkDataCRC bool, rng *rand.Rand) *WeightedInterleaveIterator {
+	w := &WeightedInterleaveIterator{rng: rng, source: -1}
+	for _, s := range sources {
+		weight := s.Weight
+		if weight < 0 {
+			weight = 0
+		}
+		w.its = append(w.its, NewIterator(s.R, bufSize, checkDataCRC))
+		w.weights = append(w.weights, weight)
+		w.total += weight
+	}
+	return w
+}
+
+// Next moves to next record
+func (w *WeightedInterleaveIterator) Next() bool {
+	w.value, w.source = nil, -1
+	for w.err == nil && w.total > 0 {
+		i := w.pick()
+		if w.its[i].Next() {
+			w.value, w.source = w.its[i].Value(), i
+			return true
+		}
+		if err := w.its[i].Err(); err != nil {
+			w.err = fmt.Errorf("source %d: %w", i, err)
+			return false
+		}
+		w.total -= w.weights[i]
+		w.weights[i] = 0
+		if w.total < 0 || !w.anyActive() {
+			w.total = 0
+		}
+	}
+	return false
+}
+
+func (w *WeightedInterleaveIterator) pick() int {
+	x := w.rng.Float64() * w.total
+	last := 0
+	for i, weight := range w.weights {
+		if weight <= 0 {
+			continue
+		}
+		if x < weight {
+			return i
+		}
+		x -= weight
+		last = i
+	}
+	// floating point rounding may leave x slightly over the last active weight.
+	return last
+}
+
+func (w *WeightedInterleaveIterator) anyActive() bool {
+	for _, weight := range w.weights {
+		if weight > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns the current record, valid until next call to Next
+func (w *WeightedInterleaveIterator) Value() []byte {
+	return w.value
+}
+
+// Source returns index of the source current record comes from, -1 when there's no current record
+func (w *WeightedInterleaveIterator) Source() int {
+	return w.source
+}
+
+// Err returns the first error of sources, wrapped with source index
+func (w *WeightedInterleaveIterator) Err() error {
+	return w.err
+}
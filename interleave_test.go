@@ -0,0 +1,53 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestWeightedInterleaveIterator(t *testing.T) {
+	sources := []WeightedSource{
+		{bytes.NewReader(writeSizedRecords(t, make([]int, 3000)...)), 3},
+		{bytes.NewReader(writeSizedRecords(t, make([]int, 1000)...)), 1},
+		{bytes.NewReader(writeSizedRecords(t, make([]int, 10)...)), 0},
+	}
+	w := NewWeightedInterleaveIterator(sources, 16, true, rand.New(rand.NewSource(1)))
+	counts := make([]int, 3)
+	firstHalf := make([]int, 3)
+	n := 0
+	for w.Next() {
+		counts[w.Source()]++
+		if n < 2000 {
+			firstHalf[w.Source()]++
+		}
+		n++
+	}
+	if err := w.Err(); err != nil {
+		t.Fatalf("read error %v", err)
+	}
+	if counts[0] != 3000 || counts[1] != 1000 || counts[2] != 0 {
+		t.Errorf("unexpected counts %v", counts)
+	}
+	if ratio := float64(firstHalf[0]) / float64(firstHalf[1]); ratio < 2.5 || ratio > 3.5 {
+		t.Errorf("expect about 3:1 ratio, actual %v", firstHalf)
+	}
+	if w.Source() != -1 || w.Value() != nil {
+		t.Errorf("expect no current record after exhaustion")
+	}
+}
+
+func TestWeightedInterleaveIteratorError(t *testing.T) {
+	bad := writeTestRecords(t, 10)
+	bad[HeaderSize]++
+	w := NewWeightedInterleaveIterator([]WeightedSource{
+		{bytes.NewReader(writeTestRecords(t, 10)), 1},
+		{bytes.NewReader(bad), 1},
+	}, 16, true, rand.New(rand.NewSource(1)))
+	for w.Next() {
+	}
+	if !errors.Is(w.Err(), ErrChecksum) {
+		t.Errorf("expect ErrChecksum, actual %v", w.Err())
+	}
+}
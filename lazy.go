@@ -0,0 +1,155 @@
+package tfrecord
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// errStaleRecord is returned by LazyRecord.Bytes when a streaming LazyIterator already moved past the record.
+var errStaleRecord = errors.New("lazy record payload is no longer available, Bytes must be called before next Next on a non-seekable reader")
+
+// LazyIterator iterates TFRecords reading only their headers, payload is read when LazyRecord.Bytes is called.
+// When most records are filtered out by length, it saves reading their payloads.
+type LazyIterator struct {
+	ra           io.ReaderAt
+	r            io.Reader
+	checkDataCRC bool
+
+	offset int64
+	record *LazyRecord
+	err    error
+}
+
+// LazyRecord is a record whose payload is not read yet.
+type LazyRecord struct {
+	// Offset is the position of the record's header, relative to where iteration started.
+	Offset int64
+	// Length is the payload length.
+	Length uint64
+
+	it         *LazyIterator
+	payload    []byte
+	payloadErr error
+	read       bool
+}
+
+// NewLazyIterator creates a LazyIterator reading from current position of r. When r is an io.ReaderAt or
+// io.ReadSeeker, unread payloads are skipped without reading and Bytes of any record can be called at any
+// time. Otherwise unread payloads are read and discarded, and Bytes must be called before next call to Next.
+// An io.ReaderAt that can't seek is read from its start.
+func NewLazyIterator(r io.Reader, checkDataCRC bool) *LazyIterator {
+	it := &LazyIterator{r: r, checkDataCRC: checkDataCRC}
+	// a seekable reader may be partly consumed, newReaderAt reads from its current position.
+	if rs, ok := r.(io.ReadSeeker); ok {
+		ra, err := newReaderAt(rs)
+		it.ra, it.err = ra, err
+	} else if ra, ok := r.(io.ReaderAt); ok {
+		it.ra = ra
+	}
+	return it
+}
+
+// Next reads header of next record. On seekable readers a truncated payload is only detected when it's read.
+func (it *LazyIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	var header [HeaderSize]byte
+	if it.ra != nil {
+		n, err := it.ra.ReadAt(header[:], it.offset)
+		if n == 0 && err == io.EOF {
+			it.record = nil
+			return false
+		}
+		if n < HeaderSize {
+			return it.fail(it.offset, err)
+		}
+	} else {
+		if it.record != nil && !it.record.read {
+			if _, err := io.CopyN(io.Discard, it.r, int64(it.record.Length)+FooterSize); err != nil {
+				return it.fail(it.record.Offset, err)
+			}
+		}
+		if _, err := io.ReadFull(it.r, header[:]); err != nil {
+			if err == io.EOF {
+				it.record = nil
+				return false
+			}
+			return it.fail(it.offset, err)
+		}
+	}
+	length, err := parseHeader(header[:])
+	if err != nil {
+		return it.fail(it.offset, &RecordError{
+			Offset:      it.offset,
+			StoredCRC:   binary.LittleEndian.Uint32(header[LengthSize:]),
+			ComputedCRC: checksum(header[:LengthSize]),
+			Err:         err,
+		})
+	}
+	it.record = &LazyRecord{Offset: it.offset, Length: length, it: it}
+	it.offset += HeaderSize + int64(length) + FooterSize
+	return true
+}
+
+func (it *LazyIterator) fail(offset int64, err error) bool {
+	if err == nil || err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = &RecordError{Offset: offset, Err: ErrTruncated}
+	}
+	it.err = err
+	it.record = nil
+	return false
+}
+
+// Record returns current record, nil when iterator not in valid state
+func (it *LazyIterator) Record() *LazyRecord {
+	return it.record
+}
+
+// Err returns any error stopping Next(), io.EOF is not considered error
+func (it *LazyIterator) Err() error {
+	return it.err
+}
+
+// Bytes reads and returns the record payload, checking its CRC if the iterator does. The payload is newly
+// allocated and read only once, later calls return the same slice.
+func (r *LazyRecord) Bytes() ([]byte, error) {
+	if r.read {
+		return r.payload, r.payloadErr
+	}
+	it := r.it
+	if err := checkRecordLen(r.Length, r.Offset); err != nil {
+		return nil, err
+	}
+	frame := make([]byte, r.Length+FooterSize)
+	if it.ra != nil {
+		if n, err := it.ra.ReadAt(frame, r.Offset+HeaderSize); n < len(frame) {
+			if err == nil || err == io.EOF {
+				err = &RecordError{Offset: r.Offset, Err: ErrTruncated}
+			}
+			return nil, err
+		}
+	} else {
+		if it.record != r {
+			return nil, errStaleRecord
+		}
+		if _, err := io.ReadFull(it.r, frame); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				err = &RecordError{Offset: r.Offset, Err: ErrTruncated}
+			}
+			it.err = err
+			return nil, err
+		}
+	}
+	payload := frame[:r.Length]
+	var err error
+	if it.checkDataCRC {
+		stored := binary.LittleEndian.Uint32(frame[r.Length:])
+		if crc := checksum(payload); crc != stored {
+			payload, err = nil, &RecordError{Offset: r.Offset, StoredCRC: stored, ComputedCRC: crc, Err: ErrChecksum}
+		}
+	}
+	r.payload, r.payloadErr, r.read = payload, err, true
+	return payload, err
+}
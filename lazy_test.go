@@ -0,0 +1,89 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestLazyIterator(t *testing.T) {
+	data := writeSizedRecords(t, 100, 2, 100, 3)
+	readers := map[string]func() io.Reader{
+		"readerAt": func() io.Reader { return bytes.NewReader(data) },
+		"seeker":   func() io.Reader { return struct{ io.ReadSeeker }{bytes.NewReader(data)} },
+		"stream":   func() io.Reader { return struct{ io.Reader }{bytes.NewReader(data)} },
+	}
+	for name, reader := range readers {
+		it := NewLazyIterator(reader(), true)
+		var records []*LazyRecord
+		for it.Next() {
+			r := it.Record()
+			records = append(records, r)
+			if r.Length < 10 {
+				payload, err := r.Bytes()
+				if err != nil || !bytes.Equal(payload, bytes.Repeat([]byte("x"), int(r.Length))) {
+					t.Errorf("%s: unexpected payload %q, err %v", name, payload, err)
+				}
+			}
+		}
+		if err := it.Err(); err != nil {
+			t.Fatalf("%s: read error %v", name, err)
+		}
+		if len(records) != 4 || records[2].Offset != 2*HeaderSize+100+2+2*FooterSize {
+			t.Fatalf("%s: unexpected records", name)
+		}
+		_, err := records[0].Bytes()
+		if name == "stream" {
+			if err != errStaleRecord {
+				t.Errorf("%s: expect stale record error, actual %v", name, err)
+			}
+		} else if err != nil {
+			t.Errorf("%s: failed reading earlier record %v", name, err)
+		}
+	}
+}
+
+func TestLazyIteratorCorrupt(t *testing.T) {
+	data := writeSizedRecords(t, 10, 10)
+	data[HeaderSize]++
+	for _, r := range []io.Reader{bytes.NewReader(data), struct{ io.Reader }{bytes.NewReader(data)}} {
+		it := NewLazyIterator(r, true)
+		if !it.Next() {
+			t.Fatalf("expect first record, err %v", it.Err())
+		}
+		if _, err := it.Record().Bytes(); !errors.Is(err, ErrChecksum) {
+			t.Errorf("expect ErrChecksum, actual %v", err)
+		}
+		if _, err := it.Record().Bytes(); !errors.Is(err, ErrChecksum) {
+			t.Errorf("expect ErrChecksum again, actual %v", err)
+		}
+		if !it.Next() || it.Next() || it.Err() != nil {
+			t.Errorf("expect corrupt payload not to stop iteration, err %v", it.Err())
+		}
+	}
+
+	it := NewLazyIterator(struct{ io.Reader }{bytes.NewReader(data[:len(data)-1])}, true)
+	for it.Next() {
+	}
+	var re *RecordError
+	if !errors.As(it.Err(), &re) || re.Err != ErrTruncated || re.Offset != HeaderSize+10+FooterSize {
+		t.Errorf("expect ErrTruncated at second record, actual %v", it.Err())
+	}
+}
+
+func TestLazyIteratorCurrentPosition(t *testing.T) {
+	data := writeSizedRecords(t, 3, 5)
+	r := bytes.NewReader(data)
+	r.Seek(FrameSize(3), io.SeekStart)
+	it := NewLazyIterator(r, true)
+	if !it.Next() || it.Record().Offset != 0 || it.Record().Length != 5 {
+		t.Fatalf("expect record of 5 bytes at start of iteration, actual %+v, err %v", it.Record(), it.Err())
+	}
+	if payload, err := it.Record().Bytes(); err != nil || len(payload) != 5 {
+		t.Errorf("expect payload of 5 bytes, actual %d, %v", len(payload), err)
+	}
+	if it.Next() || it.Err() != nil {
+		t.Errorf("expect clean end after 1 record, err %v", it.Err())
+	}
+}
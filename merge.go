@@ -0,0 +1,66 @@
+package tfrecord
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Merge concatenates records of srcs into dst and returns the number of records written. Order is stable:
+// all records of srcs[0] in file order, then all of srcs[1] and so on. Data CRCs are checked, it stops at
+// the first error.
+func Merge(dst *Writer, srcs ...io.Reader) (int, error) {
+	total := 0
+	for _, src := range srcs {
+		n, err := mergeOne(dst, src)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func mergeOne(dst *Writer, src io.Reader) (int, error) {
+	it := NewIterator(src, defaultBufSize, true)
+	n := 0
+	for it.Next() {
+		if _, err := dst.Write(it.Value()); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, it.Err()
+}
+
+// MergeFiles merges files at paths into dst like Merge, files are merged in lexicographical order of paths
+// regardless of order of paths so runs are reproducible. Errors are prefixed by path of the offending file.
+func MergeFiles(dst *Writer, paths []string) (int, error) {
+	return MergeFilesFunc(dst, paths, strings.Compare)
+}
+
+// MergeFilesFunc is like MergeFiles but orders paths by cmp, which follows slices.SortFunc convention.
+func MergeFilesFunc(dst *Writer, paths []string, cmp func(a, b string) int) (int, error) {
+	sorted := slices.Clone(paths)
+	slices.SortStableFunc(sorted, cmp)
+	total := 0
+	for _, path := range sorted {
+		n, err := mergeFile(dst, path)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return total, nil
+}
+
+func mergeFile(dst *Writer, path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return mergeOne(dst, f)
+}
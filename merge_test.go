@@ -0,0 +1,60 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeFiles(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"c", "a", "b"} {
+		buf := &bytes.Buffer{}
+		w := NewWriter(buf)
+		for _, suffix := range []string{"1", "2"} {
+			w.Write([]byte(name + suffix))
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	buf := &bytes.Buffer{}
+	n, err := MergeFiles(NewWriter(buf), paths)
+	if err != nil || n != 6 {
+		t.Fatalf("expect 6 records, actual %d, %v", n, err)
+	}
+	if actual := strings.Join(readAllStrings(t, buf.Bytes()), ","); actual != "a1,a2,b1,b2,c1,c2" {
+		t.Errorf("unexpected order %s", actual)
+	}
+
+	buf.Reset()
+	reverse := func(a, b string) int { return strings.Compare(b, a) }
+	if _, err := MergeFilesFunc(NewWriter(buf), paths, reverse); err != nil {
+		t.Fatalf("merge error %v", err)
+	}
+	if actual := strings.Join(readAllStrings(t, buf.Bytes()), ","); actual != "c1,c2,b1,b2,a1,a2" {
+		t.Errorf("unexpected order %s", actual)
+	}
+
+	missing := filepath.Join(dir, "missing")
+	_, err = MergeFiles(NewWriter(&bytes.Buffer{}), append(paths, missing))
+	if !errors.Is(err, os.ErrNotExist) || !strings.Contains(err.Error(), missing) {
+		t.Errorf("expect not exist error with path, actual %v", err)
+	}
+}
+
+func TestMergeError(t *testing.T) {
+	bad := writeTestRecords(t, 3)
+	bad[HeaderSize]++
+	n, err := Merge(NewWriter(&bytes.Buffer{}), bytes.NewReader(writeTestRecords(t, 3)), bytes.NewReader(bad))
+	if n != 3 || !errors.Is(err, ErrChecksum) {
+		t.Errorf("expect 3 records and ErrChecksum, actual %d, %v", n, err)
+	}
+}
@@ -0,0 +1,28 @@
+package tfrecord
+
+// Metrics receives iterator events, it's the hook to plug monitoring and tracing into iteration. Methods are
+// called synchronously from Next, implementations should be cheap. Optional subpackages tfotel and tfprom
+// provide OpenTelemetry and Prometheus implementations.
+type Metrics interface {
+	// RecordRead is called for every record successfully read, frameSize is the number of bytes the record
+	// takes in the stream including header and footer.
+	RecordRead(frameSize int)
+	// ChecksumFailure is called when a length or data CRC mismatch is found.
+	ChecksumFailure()
+	// IterationDone is called once when Next returns its terminal false, err is nil on clean EOF. Iteration
+	// continuing after ClearErr doesn't call it again.
+	IterationDone(err error)
+}
+
+// WithMetrics reports iterator events to m. It can be given multiple times to report to several Metrics.
+func WithMetrics(m Metrics) Option {
+	return func(it *Iterator) {
+		it.metrics = append(it.metrics, m)
+	}
+}
+
+func (it *Iterator) checksumFailure() {
+	for _, m := range it.metrics {
+		m.ChecksumFailure()
+	}
+}
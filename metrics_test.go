@@ -0,0 +1,73 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type fakeMetrics struct {
+	records   int
+	bytes     int
+	failures  int
+	done      int
+	doneError error
+}
+
+func (m *fakeMetrics) RecordRead(frameSize int) {
+	m.records++
+	m.bytes += frameSize
+}
+
+func (m *fakeMetrics) ChecksumFailure() {
+	m.failures++
+}
+
+func (m *fakeMetrics) IterationDone(err error) {
+	m.done++
+	m.doneError = err
+}
+
+func TestMetrics(t *testing.T) {
+	data := writeTestRecords(t, 10)
+	m := &fakeMetrics{}
+	it := NewIterator(bytes.NewReader(data), 16, true, WithMetrics(m))
+	for it.Next() {
+	}
+	it.Next()
+	if m.records != 10 || m.bytes != len(data) || m.failures != 0 {
+		t.Errorf("unexpected metrics %+v", m)
+	}
+	if m.done != 1 || m.doneError != nil {
+		t.Errorf("expect single clean IterationDone, actual %+v", m)
+	}
+
+	data[len(data)-1]++
+	m = &fakeMetrics{}
+	it = NewIterator(bytes.NewReader(data), 16, true, WithMetrics(m))
+	for it.Next() {
+	}
+	if m.records != 9 || m.failures != 1 || !errors.Is(m.doneError, ErrChecksum) {
+		t.Errorf("unexpected metrics %+v", m)
+	}
+}
+
+func TestMetricsClearErr(t *testing.T) {
+	data := writeTestRecords(t, 10)
+	data[HeaderSize]++
+	m := &fakeMetrics{}
+	it := NewIterator(bytes.NewReader(data), 16, true, WithMetrics(m))
+	for it.Next() {
+	}
+	if err := it.ClearErr(); err != nil {
+		t.Fatalf("clear error %v", err)
+	}
+	for it.Next() {
+	}
+	if it.Err() != nil || m.records != 9 {
+		t.Errorf("expect 9 records after ClearErr, actual %d, err %v", m.records, it.Err())
+	}
+	if m.done != 1 || !errors.Is(m.doneError, ErrChecksum) {
+		t.Errorf("expect IterationDone once, actual %+v", m)
+	}
+}
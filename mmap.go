@@ -0,0 +1,31 @@
+package tfrecord
+
+import (
+	"io"
+)
+
+// OpenMmap opens file at path as an io.ReaderAt, close releases it. On platforms supporting it the file is
+// memory-mapped, which avoids syscalls and copying into kernel buffers for random access to large local
+// files, elsewhere it falls back to reading the file with ReadAt.
+func OpenMmap(path string) (r io.ReaderAt, close func() error, err error) {
+	return openMmap(path)
+}
+
+// mmapReaderAt reads from a memory-mapped file.
+type mmapReaderAt struct {
+	data []byte
+}
+
+func (r *mmapReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if off >= int64(len(r.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
@@ -0,0 +1,80 @@
+package tfrecord
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenMmap(t *testing.T) {
+	r, closeFn, err := OpenMmap("testdata/test.tfrecord")
+	if err != nil {
+		t.Fatalf("failed opening %v", err)
+	}
+	defer closeFn()
+	out := ""
+	it := NewIterator(io.NewSectionReader(r, 0, 1<<62), 16, true)
+	for it.Next() {
+		out += string(it.Value())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("read error %v", err)
+	}
+	if expect := "HelloWorldFromTensorflow"; out != expect {
+		t.Errorf("unmatched read content, expect %s, actual %s", expect, out)
+	}
+
+	path := filepath.Join(t.TempDir(), "empty")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatalf("failed writing file %v", err)
+	}
+	r, closeEmpty, err := OpenMmap(path)
+	if err != nil {
+		t.Fatalf("failed opening empty file %v", err)
+	}
+	defer closeEmpty()
+	if _, err := r.ReadAt(make([]byte, 1), 0); err != io.EOF {
+		t.Errorf("expect EOF reading empty file, actual %v", err)
+	}
+}
+
+func benchmarkReadAt(b *testing.B, open func(path string) (io.ReaderAt, func() error, error)) {
+	path := filepath.Join(b.TempDir(), "bench.tfrecord")
+	sizes := make([]int, 10000)
+	for i := range sizes {
+		sizes[i] = 1000
+	}
+	if err := os.WriteFile(path, writeSizedRecords(b, sizes...), 0644); err != nil {
+		b.Fatalf("failed writing file %v", err)
+	}
+	r, closeFn, err := open(path)
+	if err != nil {
+		b.Fatalf("failed opening %v", err)
+	}
+	defer closeFn()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		it := NewIterator(io.NewSectionReader(r, 0, 1<<62), 4096, true)
+		for it.Next() {
+		}
+		if err := it.Err(); err != nil {
+			b.Fatalf("read error %v", err)
+		}
+	}
+}
+
+func BenchmarkMmap(b *testing.B) {
+	benchmarkReadAt(b, OpenMmap)
+}
+
+func BenchmarkFileReadAt(b *testing.B) {
+	benchmarkReadAt(b, func(path string) (io.ReaderAt, func() error, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return f, f.Close, nil
+	})
+}
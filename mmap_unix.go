@@ -0,0 +1,41 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package tfrecord
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+func openMmap(path string) (io.ReaderAt, func() error, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	size := info.Size()
+	if size == 0 {
+		return &mmapReaderAt{}, func() error { return nil }, nil
+	}
+	if int64(int(size)) != size {
+		return nil, nil, &os.PathError{Op: "mmap", Path: path, Err: syscall.EFBIG}
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, &os.PathError{Op: "mmap", Path: path, Err: err}
+	}
+	r := &mmapReaderAt{data: data}
+	return r, func() error {
+		if r.data == nil {
+			return nil
+		}
+		data := r.data
+		r.data = nil
+		return syscall.Munmap(data)
+	}, nil
+}
@@ -0,0 +1,177 @@
+package tfrecord
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MultiIterator iterates records of several sources one after another, in order of sources. Each source is
+// closed as soon as it's exhausted, so only one source is open at a time during iteration.
+type MultiIterator struct {
+	srcs         []io.ReadCloser
+	bufSize      int64
+	checkDataCRC bool
+	opts         []Option
+	// compression is compression of each source, nil when all are uncompressed.
+	compression []CompressionType
+
+	// source is index of the source being read, it == nil before first Next or after the last source.
+	source int
+	it     *Iterator
+	err    error
+	closed bool
+	// closeErrs are errors closing sources.
+	closeErrs []error
+
+	skipFailed  bool
+	onError     func(source int, err error)
+	recoverable func(error) bool
+	failed      []int
+}
+
+// NewMultiIterator creates a MultiIterator, each source is read by an Iterator created with bufSize,
+// checkDataCRC and opts. The MultiIterator takes ownership of srcs, every source is closed exactly once,
+// either when exhausted, on error, or by Close. WithAutoClose in opts is ignored, closing is always up to the
+// MultiIterator.
+func NewMultiIterator(srcs []io.ReadCloser, bufSize int64, checkDataCRC bool, opts ...Option) *MultiIterator {
+	return &MultiIterator{
+		srcs:         srcs,
+		bufSize:      bufSize,
+		checkDataCRC: checkDataCRC,
+		opts:         opts,
+		source:       -1,
+	}
+}
+
+// Source is a source of NewMixedMultiIterator with its compression.
+type Source struct {
+	R           io.ReadCloser
+	Compression CompressionType
+}
+
+// NewMixedMultiIterator creates a MultiIterator over sources of different compression, each one is
+// decompressed according to its Compression. Errors tell compression of the failing source besides its index.
+func NewMixedMultiIterator(srcs []Source, bufSize int64, checkDataCRC bool, opts ...Option) *MultiIterator {
+	readers := make([]io.ReadCloser, len(srcs))
+	compression := make([]CompressionType, len(srcs))
+	for i, src := range srcs {
+		readers[i], compression[i] = src.R, src.Compression
+	}
+	m := NewMultiIterator(readers, bufSize, checkDataCRC, opts...)
+	m.compression = compression
+	return m
+}
+
+// Next moves to next record, it returns false when all sources are exhausted or on error, after which all
+// sources are closed.
+func (m *MultiIterator) Next() bool {
+	for m.err == nil && !m.closed {
+		if m.it == nil {
+			if m.source+1 >= len(m.srcs) {
+				return false
+			}
+			m.source++
+			m.it = m.newIterator(m.source)
+		}
+		if m.it.Next() {
+			return true
+		}
+		err := m.it.Err()
+		m.closeSource(m.source)
+		m.it = nil
+		if err != nil {
+			if m.skipFailed && m.isRecoverable(err) {
+				m.failed = append(m.failed, m.source)
+				if m.onError != nil {
+					m.onError(m.source, err)
+				}
+				continue
+			}
+			m.err = m.sourceError(m.source, err)
+			m.Close()
+		}
+	}
+	return false
+}
+
+func (m *MultiIterator) newIterator(i int) *Iterator {
+	opts := m.opts[:len(m.opts):len(m.opts)]
+	if m.compression != nil {
+		opts = append(opts, WithCompression(m.compression[i]))
+	}
+	// sources are closed by m, WithAutoClose in opts would close them twice.
+	opts = append(opts, WithAutoClose(false))
+	return NewIterator(m.srcs[i], m.bufSize, m.checkDataCRC, opts...)
+}
+
+func (m *MultiIterator) sourceError(i int, err error) error {
+	if m.compression == nil {
+		return fmt.Errorf("source %d: %w", i, err)
+	}
+	return fmt.Errorf("source %d (%v): %w", i, m.compression[i], err)
+}
+
+func (m *MultiIterator) closeSource(i int) {
+	if m.srcs[i] == nil {
+		return
+	}
+	if err := m.srcs[i].Close(); err != nil {
+		m.closeErrs = append(m.closeErrs, fmt.Errorf("closing source %d: %w", i, err))
+	}
+	m.srcs[i] = nil
+}
+
+// SkipFailedSources makes the iterator move on to next source when a source fails reading, for corruption or
+// IO error, instead of stopping. onError, if not nil, is called with index of each failing source and its
+// error. Records of a failing source read before the error are still returned. It must be called before
+// first Next.
+func (m *MultiIterator) SkipFailedSources(onError func(source int, err error)) {
+	m.skipFailed, m.onError = true, onError
+}
+
+// SetRecoverableError sets fn to decide which source errors SkipFailedSources skips, other errors stop
+// iteration. By default all errors are skipped but context cancellation, errors matching context.Canceled or
+// context.DeadlineExceeded, which always stops iteration whatever fn returns.
+func (m *MultiIterator) SetRecoverableError(fn func(error) bool) {
+	m.recoverable = fn
+}
+
+func (m *MultiIterator) isRecoverable(err error) bool {
+	return !isContextErr(err) && (m.recoverable == nil || m.recoverable(err))
+}
+
+// FailedSources returns indexes of sources skipped for errors, see SkipFailedSources.
+func (m *MultiIterator) FailedSources() []int {
+	return m.failed
+}
+
+// Value returns the current record, valid until next call to Next
+func (m *MultiIterator) Value() []byte {
+	if m.it == nil {
+		return nil
+	}
+	return m.it.Value()
+}
+
+// Source returns index of the source current record comes from.
+func (m *MultiIterator) Source() int {
+	return m.source
+}
+
+// Err returns the read error stopping iteration joined with errors closing sources, nil if none happened.
+func (m *MultiIterator) Err() error {
+	return errors.Join(append([]error{m.err}, m.closeErrs...)...)
+}
+
+// Close closes the current source and all sources not read yet, for aborting iteration early. Next returns
+// false after Close. Close errors are reported by Err as well.
+func (m *MultiIterator) Close() error {
+	n := len(m.closeErrs)
+	m.it = nil
+	for i := range m.srcs {
+		m.closeSource(i)
+	}
+	m.closed = true
+	return errors.Join(m.closeErrs[n:]...)
+}
@@ -0,0 +1,163 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type closeCounter struct {
+	io.Reader
+	closes int
+	err    error
+}
+
+func (c *closeCounter) Close() error {
+	c.closes++
+	return c.err
+}
+
+func newCloseCounters(data ...[]byte) ([]*closeCounter, []io.ReadCloser) {
+	var counters []*closeCounter
+	var srcs []io.ReadCloser
+	for _, d := range data {
+		c := &closeCounter{Reader: bytes.NewReader(d)}
+		counters = append(counters, c)
+		srcs = append(srcs, c)
+	}
+	return counters, srcs
+}
+
+func TestMultiIterator(t *testing.T) {
+	errClose := errors.New("close failed")
+	counters, srcs := newCloseCounters(writeTestRecords(t, 3), nil, writeTestRecords(t, 2))
+	counters[1].err = errClose
+	m := NewMultiIterator(srcs, 16, true)
+	var values []string
+	for m.Next() {
+		values = append(values, string(m.Value()))
+		if m.Source() == 0 && counters[0].closes != 0 {
+			t.Errorf("source closed before exhausted")
+		}
+	}
+	if len(values) != 5 || values[3] != "0" || values[4] != "1" {
+		t.Errorf("unexpected records %v", values)
+	}
+	if !errors.Is(m.Err(), errClose) {
+		t.Errorf("expect close error, actual %v", m.Err())
+	}
+	m.Close()
+	for i, c := range counters {
+		if c.closes != 1 {
+			t.Errorf("source %d closed %d times", i, c.closes)
+		}
+	}
+}
+
+func TestMultiIteratorAutoClose(t *testing.T) {
+	counters, srcs := newCloseCounters(writeTestRecords(t, 3), writeTestRecords(t, 2))
+	m := NewMultiIterator(srcs, 16, true, WithAutoClose(true))
+	n := 0
+	for m.Next() {
+		n++
+	}
+	if n != 5 || m.Err() != nil {
+		t.Errorf("expect 5 records, actual %d, err %v", n, m.Err())
+	}
+	m.Close()
+	for i, c := range counters {
+		if c.closes != 1 {
+			t.Errorf("source %d closed %d times with WithAutoClose", i, c.closes)
+		}
+	}
+}
+
+func TestMultiIteratorAbort(t *testing.T) {
+	bad := writeTestRecords(t, 3)
+	bad[HeaderSize]++
+	counters, srcs := newCloseCounters(writeTestRecords(t, 3), bad, writeTestRecords(t, 3))
+	m := NewMultiIterator(srcs, 16, true)
+	n := 0
+	for m.Next() {
+		n++
+	}
+	if n != 3 || !errors.Is(m.Err(), ErrChecksum) {
+		t.Errorf("expect ErrChecksum after 3 records, actual %d, %v", n, m.Err())
+	}
+
+	counters2, srcs := newCloseCounters(writeTestRecords(t, 3), writeTestRecords(t, 3))
+	m = NewMultiIterator(srcs, 16, true)
+	m.Next()
+	if err := m.Close(); err != nil {
+		t.Errorf("close error %v", err)
+	}
+	if m.Next() || m.Err() != nil {
+		t.Errorf("expect clean stop after Close, actual %v", m.Err())
+	}
+	for i, c := range append(counters, counters2...) {
+		if c.closes != 1 {
+			t.Errorf("source %d closed %d times", i, c.closes)
+		}
+	}
+}
+
+func TestMultiIteratorSkipFailed(t *testing.T) {
+	bad := writeTestRecords(t, 3)
+	bad[HeaderSize]++
+	counters, srcs := newCloseCounters(writeTestRecords(t, 3), bad, writeTestRecords(t, 2), bad[:5])
+	m := NewMultiIterator(srcs, 16, true)
+	var reported []error
+	m.SkipFailedSources(func(source int, err error) {
+		reported = append(reported, err)
+	})
+	n := 0
+	for m.Next() {
+		n++
+	}
+	if n != 5 || m.Err() != nil {
+		t.Errorf("expect 5 records without error, actual %d, %v", n, m.Err())
+	}
+	if failed := m.FailedSources(); len(failed) != 2 || failed[0] != 1 || failed[1] != 3 {
+		t.Errorf("expect failed sources [1 3], actual %v", failed)
+	}
+	if len(reported) != 2 || !errors.Is(reported[0], ErrChecksum) || !errors.Is(reported[1], ErrTruncated) {
+		t.Errorf("unexpected reported errors %v", reported)
+	}
+	for i, c := range counters {
+		if c.closes != 1 {
+			t.Errorf("source %d closed %d times", i, c.closes)
+		}
+	}
+}
+
+func TestMixedMultiIterator(t *testing.T) {
+	data := writeTestRecords(t, 2)
+	counters, srcs := newCloseCounters(data, compress(t, GzipCompression, data), compress(t, ZlibCompression, data))
+	var sources []Source
+	for i, c := range []CompressionType{NoCompression, GzipCompression, ZlibCompression} {
+		sources = append(sources, Source{R: srcs[i], Compression: c})
+	}
+	m := NewMixedMultiIterator(sources, 16, true)
+	n := 0
+	for m.Next() {
+		n++
+	}
+	if m.Err() != nil || n != 6 {
+		t.Errorf("expect 6 records, actual %d, err %v", n, m.Err())
+	}
+	for i, c := range counters {
+		if c.closes != 1 {
+			t.Errorf("source %d closed %d times", i, c.closes)
+		}
+	}
+
+	_, srcs = newCloseCounters(data, data)
+	m = NewMixedMultiIterator([]Source{{srcs[0], NoCompression}, {srcs[1], GzipCompression}}, 16, true)
+	for m.Next() {
+	}
+	if err := m.Err(); err == nil || !strings.Contains(err.Error(), "source 1 (gzip)") {
+		t.Errorf("expect error of source 1 in gzip, actual %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package tfrecord
+
+import (
+	"fmt"
+	"io"
+)
+
+// NewMultiWriter creates a Writer writing every record to all of ws, each record is framed once and the same
+// bytes are written to every sink in order. Unlike io.MultiWriter under a Writer, a failed sink stops all later
+// writes, so sinks never go further out of sync, the error tells index of the failed sink. Sinks before it
+// already have the failed record.
+func NewMultiWriter(ws ...io.Writer) *Writer {
+	return NewWriter(&fanoutWriter{ws: ws})
+}
+
+// fanoutWriter writes to all ws, it fails all writes after the first failure.
+type fanoutWriter struct {
+	ws  []io.Writer
+	err error
+}
+
+func (f *fanoutWriter) Write(p []byte) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	for i, w := range f.ws {
+		if err := writeFull(w, p); err != nil {
+			f.err = fmt.Errorf("sink %d: %w", i, err)
+			return 0, f.err
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,41 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMultiWriter(t *testing.T) {
+	var a, b bytes.Buffer
+	cw := &countingWriter{}
+	w := NewMultiWriter(&a, &b, cw)
+	for i := 0; i < 10; i++ {
+		if _, err := w.Write([]byte(strconv.Itoa(i))); err != nil {
+			t.Fatalf("write error %v", err)
+		}
+	}
+	if expect := writeTestRecords(t, 10); !bytes.Equal(a.Bytes(), expect) || !bytes.Equal(b.Bytes(), expect) {
+		t.Errorf("expect identical records in all sinks")
+	}
+	if cw.writes != 10 {
+		t.Errorf("expect 1 write per record, actual %d", cw.writes)
+	}
+}
+
+func TestMultiWriterError(t *testing.T) {
+	var a, c bytes.Buffer
+	errSink := errors.New("sink failed")
+	w := NewMultiWriter(&a, failingWriter{errSink}, &c)
+	if _, err := w.Write([]byte("x")); !errors.Is(err, errSink) || !strings.Contains(err.Error(), "sink 1") {
+		t.Errorf("expect sink 1 failure, actual %v", err)
+	}
+	if _, err := w.Write([]byte("y")); !errors.Is(err, errSink) {
+		t.Errorf("expect later writes to fail, actual %v", err)
+	}
+	if a.Len() != int(FrameSize(1)) || c.Len() != 0 {
+		t.Errorf("expect only record before failure in first sink, actual %d, %d bytes", a.Len(), c.Len())
+	}
+}
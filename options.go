@@ -0,0 +1,123 @@
+package tfrecord
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Option configures optional Iterator behaviors.
+type Option func(*Iterator)
+
+// WriterOption configures optional Writer behaviors.
+type WriterOption func(*Writer)
+
+// WithAutoClose makes the iterator close its underlying reader, if it implements io.Closer, once Next returns
+// false at a clean EOF or on error. The reader is closed exactly once, its close error is reported by Err if no
+// other error happened.
+func WithAutoClose(enabled bool) Option {
+	return func(it *Iterator) {
+		it.autoClose = enabled
+	}
+}
+
+// WithCorruptRecords makes Next return records failing data CRC check instead of stopping with an error, so
+// caller can quarantine them, LastCRCValid tells whether current record is intact. Length CRC failures still
+// stop iteration, since stream position can't be trusted after them.
+func WithCorruptRecords(enabled bool) Option {
+	return func(it *Iterator) {
+		it.keepCorrupt = enabled
+	}
+}
+
+// deadlineReader is implemented by readers supporting read deadline, like net.Conn and os.File.
+type deadlineReader interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// WithReadTimeout sets a deadline of d for reading each record, when the underlying reader supports it through
+// a SetReadDeadline(time.Time) error method like net.Conn. Timeout errors are reported by Err, they match
+// os.ErrDeadlineExceeded with errors.Is. The option is silently ignored for readers without deadline support.
+func WithReadTimeout(d time.Duration) Option {
+	return func(it *Iterator) {
+		it.readTimeout = d
+	}
+}
+
+// WithMaxTotalBuffer caps memory the iterator holds for records to n bytes, its preallocated buffer included,
+// which is shrunk to n if larger. Next fails with an error wrapping ErrRecordTooLarge instead of allocating for
+// a record that doesn't fit in the cap. It helps running many iterators under a global memory budget.
+func WithMaxTotalBuffer(n int64) Option {
+	return func(it *Iterator) {
+		it.maxBuffer = n
+	}
+}
+
+// WithMaxWriteSize makes the writer reject records larger than n bytes, Write and WriteFrom return an error
+// wrapping ErrRecordTooLarge before writing anything, so files stay readable by readers limited with
+// WithMaxTotalBuffer.
+func WithMaxWriteSize(n int) WriterOption {
+	return func(w *Writer) {
+		w.maxSize = n
+	}
+}
+
+// checkSize returns an error if a record of n bytes exceeds WithMaxWriteSize.
+func (w *Writer) checkSize(n uint64) error {
+	if w.maxSize > 0 && n > uint64(w.maxSize) {
+		return fmt.Errorf("record of %d bytes exceeds write size limit of %d bytes: %w", n, w.maxSize, ErrRecordTooLarge)
+	}
+	return nil
+}
+
+// OversizePolicy decides how iterator handles records larger than its buffer.
+type OversizePolicy int
+
+const (
+	// OversizeAllocate allocates a new buffer for each oversize record and keeps the original buffer, it's the
+	// default.
+	OversizeAllocate OversizePolicy = iota
+	// OversizeGrow replaces the buffer with one fitting the oversize record, later records reuse it. It suits
+	// datasets with a few large records among many small ones, at the cost of holding the largest buffer.
+	OversizeGrow
+	// OversizeError stops iteration with an error wrapping ErrBufferTooSmall.
+	OversizeError
+)
+
+// WithOversizePolicy sets how records larger than the iterator buffer are handled, default is OversizeAllocate.
+func WithOversizePolicy(p OversizePolicy) Option {
+	return func(it *Iterator) {
+		it.oversize = p
+	}
+}
+
+// WithReallocCallback sets fn to be called with the record length whenever a record doesn't fit in the
+// iterator buffer and a buffer is allocated for it, growing the buffer included, to detect a too small bufSize.
+// Records read ahead with WithReadahead always get their own buffer, fn is only called for those larger than
+// the iterator buffer.
+func WithReallocCallback(fn func(recordLen uint64)) Option {
+	return func(it *Iterator) {
+		it.onRealloc = fn
+	}
+}
+
+// ErrCountMismatch is error returned when a well-formed stream doesn't have the expected number of records.
+var ErrCountMismatch = errors.New("TFRecord count mismatch")
+
+// WithExpectedCount makes the iterator check it read exactly n records when reaching clean end of stream,
+// Err returns an error wrapping ErrCountMismatch otherwise. It catches files truncated at a record boundary
+// or with extra records, which are otherwise well-formed. For a resumed iterator n is the total of the file,
+// records read before the checkpoint included.
+func WithExpectedCount(n int) Option {
+	return func(it *Iterator) {
+		it.expectCount = int64(n)
+	}
+}
+
+// checkCount checks number of records read at clean end of stream against WithExpectedCount.
+func (it *Iterator) checkCount() error {
+	if it.expectCount >= 0 && it.ordinal != it.expectCount {
+		return fmt.Errorf("read %d records, expect %d: %w", it.ordinal, it.expectCount, ErrCountMismatch)
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+type countingCloser struct {
+	*bytes.Reader
+	closed   int
+	closeErr error
+}
+
+func (c *countingCloser) Close() error {
+	c.closed++
+	return c.closeErr
+}
+
+func TestAutoClose(t *testing.T) {
+	data := writeTestRecords(t, 3)
+	r := &countingCloser{Reader: bytes.NewReader(data)}
+	it := NewIterator(r, 16, true, WithAutoClose(true))
+	n := 0
+	for it.Next() {
+		if r.closed != 0 {
+			t.Fatalf("reader closed before iteration finished")
+		}
+		n++
+	}
+	it.Next()
+	if n != 3 {
+		t.Errorf("expect 3 records, actual %d", n)
+	}
+	if r.closed != 1 {
+		t.Errorf("expect reader closed once, actual %d", r.closed)
+	}
+	if err := it.Err(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestAutoCloseError(t *testing.T) {
+	errClose := errors.New("close failed")
+	r := &countingCloser{Reader: bytes.NewReader(writeTestRecords(t, 1)), closeErr: errClose}
+	it := NewIterator(r, 16, true, WithAutoClose(true))
+	for it.Next() {
+	}
+	if err := it.Err(); err != errClose {
+		t.Errorf("expect close error, actual %v", err)
+	}
+
+	data := writeTestRecords(t, 1)
+	data[0]++
+	r = &countingCloser{Reader: bytes.NewReader(data), closeErr: errClose}
+	it = NewIterator(r, 16, true, WithAutoClose(true))
+	for it.Next() {
+	}
+	if err := it.Err(); !errors.Is(err, ErrChecksum) {
+		t.Errorf("expect checksum error to take precedence, actual %v", err)
+	}
+	if r.closed != 1 {
+		t.Errorf("expect reader closed once, actual %d", r.closed)
+	}
+}
+
+func TestNoAutoClose(t *testing.T) {
+	r := &countingCloser{Reader: bytes.NewReader(writeTestRecords(t, 1))}
+	it := NewIterator(r, 16, true)
+	for it.Next() {
+	}
+	if r.closed != 0 {
+		t.Errorf("reader should not be closed without WithAutoClose")
+	}
+}
+
+func TestCorruptRecords(t *testing.T) {
+	data := writeTestRecords(t, 3)
+	frameSize := HeaderSize + 1 + FooterSize
+	data[frameSize+HeaderSize] = 'x'
+
+	it := NewIterator(bytes.NewReader(data), 16, true, WithCorruptRecords(true))
+	var read string
+	var valid []bool
+	for it.Next() {
+		read += string(it.Value())
+		valid = append(valid, it.LastCRCValid())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if read != "0x2" || len(valid) != 3 || !valid[0] || valid[1] || !valid[2] {
+		t.Errorf("unexpected records %q, valid %v", read, valid)
+	}
+
+	data[frameSize]++
+	it = NewIterator(bytes.NewReader(data), 16, true, WithCorruptRecords(true))
+	for it.Next() {
+	}
+	if !errors.Is(it.Err(), ErrChecksum) {
+		t.Errorf("expect length checksum failure to stop iteration, actual %v", it.Err())
+	}
+}
+
+func TestReadTimeout(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	data := writeTestRecords(t, 2)
+	frameSize := HeaderSize + 1 + FooterSize
+	go func() {
+		// second record is never completed.
+		server.Write(data[:frameSize+5])
+	}()
+
+	it := NewIterator(client, 16, true, WithReadTimeout(50*time.Millisecond))
+	if !it.Next() {
+		t.Fatalf("expect first record, err %v", it.Err())
+	}
+	start := time.Now()
+	if it.Next() {
+		t.Fatalf("expect timeout reading second record")
+	}
+	if !errors.Is(it.Err(), os.ErrDeadlineExceeded) {
+		t.Errorf("expect timeout error, actual %v", it.Err())
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("timeout took %v", elapsed)
+	}
+
+	// readers without deadline support ignore the option.
+	it = NewIterator(bytes.NewReader(data), 16, true, WithReadTimeout(time.Nanosecond))
+	for it.Next() {
+	}
+	if it.Err() != nil {
+		t.Errorf("unexpected error %v", it.Err())
+	}
+}
+
+func TestMaxTotalBuffer(t *testing.T) {
+	data := writeSizedRecords(t, 10, 30, 50)
+	it := NewIterator(bytes.NewReader(data), 20, true, WithMaxTotalBuffer(50))
+	n := 0
+	for it.Next() {
+		n++
+	}
+	if n != 2 || !errors.Is(it.Err(), ErrRecordTooLarge) {
+		t.Errorf("expect stop at third record, actual %d, err %v", n, it.Err())
+	}
+
+	it = NewIterator(bytes.NewReader(data), 100, true, WithMaxTotalBuffer(40))
+	if len(it.preBuf) != 40 {
+		t.Errorf("expect buffer capped to 40, actual %d", len(it.preBuf))
+	}
+	n = 0
+	for it.Next() {
+		n++
+	}
+	if n != 2 || !errors.Is(it.Err(), ErrRecordTooLarge) {
+		t.Errorf("expect stop at third record, actual %d, err %v", n, it.Err())
+	}
+}
+
+func TestOversizePolicy(t *testing.T) {
+	data := writeSizedRecords(t, 10, 100, 50, 200)
+	for _, c := range []struct {
+		policy  OversizePolicy
+		records int
+		bufSize int
+		err     error
+	}{
+		{OversizeAllocate, 4, 16, nil},
+		{OversizeGrow, 4, 200, nil},
+		{OversizeError, 1, 16, ErrBufferTooSmall},
+	} {
+		it := NewIterator(bytes.NewReader(data), 16, true, WithOversizePolicy(c.policy))
+		n := 0
+		for it.Next() {
+			if len(it.Value()) != []int{10, 100, 50, 200}[n] {
+				t.Errorf("policy %d: unexpected record length %d", c.policy, len(it.Value()))
+			}
+			n++
+		}
+		if n != c.records || !errors.Is(it.Err(), c.err) || (c.err == nil && it.Err() != nil) {
+			t.Errorf("policy %d: expect %d records, err %v, actual %d, err %v", c.policy, c.records, c.err, n, it.Err())
+		}
+		if len(it.preBuf) != c.bufSize {
+			t.Errorf("policy %d: expect buffer size %d, actual %d", c.policy, c.bufSize, len(it.preBuf))
+		}
+	}
+
+	it := NewIterator(bytes.NewReader(data), 16, true, WithOversizePolicy(OversizeGrow), WithMaxTotalBuffer(150))
+	n := 0
+	for it.Next() {
+		n++
+	}
+	if n != 3 || !errors.Is(it.Err(), ErrRecordTooLarge) {
+		t.Errorf("expect grow limited by total buffer, actual %d records, err %v", n, it.Err())
+	}
+}
+
+func benchmarkOversizePolicy(b *testing.B, policy OversizePolicy) {
+	sizes := make([]int, 1000)
+	for i := range sizes {
+		sizes[i] = 100
+		if i%100 == 0 {
+			sizes[i] = 64 * 1024
+		}
+	}
+	data := writeSizedRecords(b, sizes...)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		it := NewIterator(bytes.NewReader(data), 1024, true, WithOversizePolicy(policy))
+		for it.Next() {
+		}
+	}
+}
+
+func TestExpectedCount(t *testing.T) {
+	data := writeTestRecords(t, 5)
+	for _, c := range []struct {
+		expect   int
+		mismatch bool
+	}{{5, false}, {4, true}, {6, true}, {0, true}} {
+		it := NewIterator(bytes.NewReader(data), 16, true, WithExpectedCount(c.expect))
+		n := 0
+		for it.Next() {
+			n++
+		}
+		if n != 5 || errors.Is(it.Err(), ErrCountMismatch) != c.mismatch {
+			t.Errorf("expect %d: unexpected result, %d records, %v", c.expect, n, it.Err())
+		}
+	}
+	it := NewIterator(bytes.NewReader(data), 16, true, WithExpectedCount(5))
+	for {
+		if _, _, err := it.NextStream(); err != nil {
+			if err != io.EOF {
+				t.Errorf("expect io.EOF from NextStream, actual %v", err)
+			}
+			break
+		}
+	}
+}
+
+func BenchmarkOversizeAllocate(b *testing.B) {
+	benchmarkOversizePolicy(b, OversizeAllocate)
+}
+
+func BenchmarkOversizeGrow(b *testing.B) {
+	benchmarkOversizePolicy(b, OversizeGrow)
+}
+
+func TestReallocCallback(t *testing.T) {
+	data := writeSizedRecords(t, 4, 20, 8, 30, 20)
+	var reallocs []uint64
+	it := NewIterator(bytes.NewReader(data), 16, true, WithReallocCallback(func(n uint64) {
+		reallocs = append(reallocs, n)
+	}))
+	for it.Next() {
+	}
+	if it.Err() != nil || len(reallocs) != 3 || reallocs[0] != 20 || reallocs[1] != 30 {
+		t.Errorf("expect reallocs for oversize records, actual %v, err %v", reallocs, it.Err())
+	}
+
+	reallocs = nil
+	it = NewIterator(bytes.NewReader(data), 16, true, WithOversizePolicy(OversizeGrow), WithReallocCallback(func(n uint64) {
+		reallocs = append(reallocs, n)
+	}))
+	for it.Next() {
+	}
+	if it.Err() != nil || len(reallocs) != 2 {
+		t.Errorf("expect reallocs only when growing, actual %v, err %v", reallocs, it.Err())
+	}
+}
+
+func TestMaxWriteSize(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, WithMaxWriteSize(4))
+	if _, err := w.Write([]byte("1234")); err != nil {
+		t.Fatalf("write error %v", err)
+	}
+	if _, err := w.Write([]byte("12345")); !errors.Is(err, ErrRecordTooLarge) {
+		t.Errorf("expect ErrRecordTooLarge, actual %v", err)
+	}
+	if _, err := w.WriteFrom(bytes.NewReader(make([]byte, 5)), 5); !errors.Is(err, ErrRecordTooLarge) {
+		t.Errorf("expect ErrRecordTooLarge from WriteFrom, actual %v", err)
+	}
+	if buf.Len() != int(FrameSize(4)) {
+		t.Errorf("expect nothing written for oversize records, actual %d bytes", buf.Len())
+	}
+}
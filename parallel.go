@@ -0,0 +1,64 @@
+package tfrecord
+
+import (
+	"sync"
+)
+
+// ParallelForEach reads records from it and calls fn on them from workers goroutines, with no ordering
+// guarantee. Each record passed to fn is a copy owned by fn. It returns the first error returned by fn, or
+// the iterator error if reading fails first. Once an error happens, no more records are dispatched and
+// ParallelForEach returns after all running workers finish.
+func ParallelForEach(it *Iterator, workers int, fn func(record []byte) error) error {
+	if workers <= 0 {
+		workers = 1
+	}
+	records := make(chan []byte, workers)
+	done := make(chan struct{})
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			close(done)
+		})
+	}
+
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for record := range records {
+				select {
+				case <-done:
+					continue
+				default:
+				}
+				if err := fn(record); err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for it.Next() {
+		value := it.Value()
+		record := make([]byte, len(value))
+		copy(record, value)
+		select {
+		case records <- record:
+		case <-done:
+			break dispatch
+		}
+	}
+	close(records)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return it.Err()
+}
@@ -0,0 +1,68 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func writeTestRecords(t testing.TB, n int) []byte {
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	for i := 0; i < n; i++ {
+		if _, err := w.Write([]byte(strconv.Itoa(i))); err != nil {
+			t.Fatalf("failed writing record %d, %v", i, err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestParallelForEach(t *testing.T) {
+	data := writeTestRecords(t, 1000)
+	var sum int64
+	it := NewIterator(bytes.NewReader(data), 16, true)
+	err := ParallelForEach(it, 8, func(record []byte) error {
+		v, err := strconv.Atoi(string(record))
+		if err != nil {
+			return err
+		}
+		atomic.AddInt64(&sum, int64(v))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if expect := int64(999 * 1000 / 2); sum != expect {
+		t.Errorf("unmatched sum, expect %d, actual %d", expect, sum)
+	}
+}
+
+func TestParallelForEachError(t *testing.T) {
+	data := writeTestRecords(t, 1000)
+	errStop := errors.New("stop")
+	before := runtime.NumGoroutine()
+	for round := 0; round < 100; round++ {
+		it := NewIterator(bytes.NewReader(data), 16, true)
+		err := ParallelForEach(it, 8, func(record []byte) error {
+			if string(record) == "500" {
+				return errStop
+			}
+			return nil
+		})
+		if err != errStop {
+			t.Fatalf("expect error %v, actual %v", errStop, err)
+		}
+	}
+	// Give exiting goroutines a moment to be accounted.
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("goroutine leak, before %d, after %d", before, after)
+	}
+}
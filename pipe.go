@@ -0,0 +1,27 @@
+package tfrecord
+
+import (
+	"io"
+)
+
+// Pipe reads records from src, applies transform and writes results to dst, it returns the number of records
+// written. Records for which transform returns (nil, nil) are dropped, a non-nil error from transform stops the
+// pipeline and is returned. The record passed to transform is only valid during the call.
+func Pipe(src io.Reader, dst *Writer, transform func([]byte) ([]byte, error), checkDataCRC bool) (int, error) {
+	it := NewIterator(src, defaultBufSize, checkDataCRC)
+	n := 0
+	for it.Next() {
+		out, err := transform(it.Value())
+		if err != nil {
+			return n, err
+		}
+		if out == nil {
+			continue
+		}
+		if _, err := dst.Write(out); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, it.Err()
+}
@@ -0,0 +1,46 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPipe(t *testing.T) {
+	src := writeTestRecords(t, 10)
+	dst := &bytes.Buffer{}
+	n, err := Pipe(bytes.NewReader(src), NewWriter(dst), func(record []byte) ([]byte, error) {
+		v, err := strconv.Atoi(string(record))
+		if err != nil {
+			return nil, err
+		}
+		if v%2 != 0 {
+			return nil, nil
+		}
+		return []byte(strconv.Itoa(v * 10)), nil
+	}, true)
+	if err != nil || n != 5 {
+		t.Fatalf("expect 5 records written, actual %d, err %v", n, err)
+	}
+	it := NewIterator(bytes.NewReader(dst.Bytes()), 16, true)
+	var out []string
+	for it.Next() {
+		out = append(out, string(it.Value()))
+	}
+	if expect := "0,20,40,60,80"; strings.Join(out, ",") != expect {
+		t.Errorf("unmatched output, expect %s, actual %s", expect, strings.Join(out, ","))
+	}
+
+	errStop := errors.New("stop")
+	n, err = Pipe(bytes.NewReader(src), NewWriter(&bytes.Buffer{}), func(record []byte) ([]byte, error) {
+		if string(record) == "3" {
+			return nil, errStop
+		}
+		return record, nil
+	}, true)
+	if err != errStop || n != 3 {
+		t.Errorf("expect stop after 3 records, actual %d, err %v", n, err)
+	}
+}
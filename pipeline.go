@@ -0,0 +1,108 @@
+package tfrecord
+
+import "io"
+
+// Builder composes record processing stages over an Iterator, see Pipeline.
+type Builder struct {
+	r      io.Reader
+	opts   []Option
+	stages []func([]byte) ([]byte, bool, error)
+	batch  int
+}
+
+// Pipeline starts a Builder reading records from r with an Iterator created with opts, data CRC is checked.
+// Stages are added with Filter, Map and Batch, Build returns the composed iterator.
+func Pipeline(r io.Reader, opts ...Option) *Builder {
+	return &Builder{r: r, opts: opts}
+}
+
+// Filter adds a stage dropping records pred returns false for. The record passed to pred is only valid during
+// the call.
+func (b *Builder) Filter(pred func([]byte) bool) *Builder {
+	b.stages = append(b.stages, func(record []byte) ([]byte, bool, error) {
+		return record, pred(record), nil
+	})
+	return b
+}
+
+// Map adds a stage replacing records with what fn returns, an error from fn stops iteration. The record passed
+// to fn is only valid during the call, fn may return it or a slice of it.
+func (b *Builder) Map(fn func([]byte) ([]byte, error)) *Builder {
+	b.stages = append(b.stages, func(record []byte) ([]byte, bool, error) {
+		record, err := fn(record)
+		return record, err == nil, err
+	})
+	return b
+}
+
+// Batch groups records coming out of the stages into batches of n records, the last one may be smaller. Filter
+// and Map stages always apply to single records, in the order they are added, regardless of where Batch is
+// called. n less than 1 is treated as 1.
+func (b *Builder) Batch(n int) *Builder {
+	b.batch = max(n, 1)
+	return b
+}
+
+// Build returns the composed iterator.
+func (b *Builder) Build() *PipelineIterator {
+	return &PipelineIterator{
+		it:     NewIterator(b.r, defaultBufSize, true, b.opts...),
+		stages: b.stages,
+		batch:  b.batch,
+	}
+}
+
+// PipelineIterator iterates results of a Builder.
+type PipelineIterator struct {
+	it     *Iterator
+	stages []func([]byte) ([]byte, bool, error)
+	batch  int
+
+	value [][]byte
+	err   error
+}
+
+// Next moves to next record, or next batch with Batch.
+func (p *PipelineIterator) Next() bool {
+	p.value = nil
+	if p.err != nil {
+		return false
+	}
+	n := max(p.batch, 1)
+	for len(p.value) < n && p.it.Next() {
+		record, keep := p.it.Value(), true
+		for _, stage := range p.stages {
+			if record, keep, p.err = stage(record); !keep {
+				break
+			}
+		}
+		if p.err != nil {
+			p.value = nil
+			return false
+		}
+		if !keep {
+			continue
+		}
+		// Batched records are retained across reads, copy them out of the iterator buffer, a mapped record may
+		// alias it as well.
+		if p.batch > 0 && !(p.it.ValueOwned() && len(p.stages) == 0) {
+			record = append([]byte(nil), record...)
+		}
+		p.value = append(p.value, record)
+	}
+	return len(p.value) > 0 && p.it.Err() == nil
+}
+
+// Value returns the current batch, a single record without Batch. Without Batch the record is only valid until
+// next call to Next, batched records are copies safe to retain.
+func (p *PipelineIterator) Value() [][]byte {
+	return p.value
+}
+
+// Err returns error from Map stages or the underlying iterator.
+func (p *PipelineIterator) Err() error {
+	if p.err != nil {
+		return p.err
+	}
+	return p.it.Err()
+}
@@ -0,0 +1,77 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func ExamplePipeline() {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for i := 0; i < 10; i++ {
+		w.Write([]byte(strconv.Itoa(i)))
+	}
+
+	it := Pipeline(&buf).
+		Filter(func(record []byte) bool { return record[0]%2 == 0 }).
+		Map(func(record []byte) ([]byte, error) { return append([]byte("#"), record...), nil }).
+		Batch(2).
+		Build()
+	for it.Next() {
+		fmt.Printf("%q\n", it.Value())
+	}
+	if err := it.Err(); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// ["#0" "#2"]
+	// ["#4" "#6"]
+	// ["#8"]
+}
+
+func TestPipelineBatchCopies(t *testing.T) {
+	data := writeTestRecords(t, 10)
+	it := Pipeline(bytes.NewReader(data)).Batch(3).Build()
+	var records [][]byte
+	for it.Next() {
+		records = append(records, it.Value()...)
+	}
+	if it.Err() != nil || len(records) != 10 {
+		t.Fatalf("expect 10 records, actual %d, err %v", len(records), it.Err())
+	}
+	for i, record := range records {
+		if string(record) != strconv.Itoa(i) {
+			t.Errorf("batched record %d changed to %q after later reads", i, record)
+		}
+	}
+
+	// Map returning a slice of the iterator buffer must be copied as well.
+	it = Pipeline(bytes.NewReader(data)).Map(func(record []byte) ([]byte, error) { return record[:1], nil }).Batch(10).Build()
+	if !it.Next() || len(it.Value()) != 10 || string(it.Value()[0]) != "0" {
+		t.Errorf("expect first record intact in batch, actual %q, err %v", it.Value(), it.Err())
+	}
+}
+
+func TestPipelineError(t *testing.T) {
+	data := writeTestRecords(t, 10)
+	errMap := errors.New("map failed")
+	it := Pipeline(bytes.NewReader(data)).Map(func(record []byte) ([]byte, error) {
+		if string(record) == "3" {
+			return nil, errMap
+		}
+		return record, nil
+	}).Build()
+	n := 0
+	for it.Next() {
+		if len(it.Value()) != 1 {
+			t.Fatalf("expect single record without Batch, actual %d", len(it.Value()))
+		}
+		n++
+	}
+	if n != 3 || !errors.Is(it.Err(), errMap) {
+		t.Errorf("expect map error after 3 records, actual %d, err %v", n, it.Err())
+	}
+}
@@ -0,0 +1,79 @@
+package tfrecord
+
+import (
+	"io"
+	"sync"
+)
+
+// PipelinedWriter writes TFRecords computing CRCs of a record in the calling goroutine while the previous
+// record is being written to the destination by a background goroutine, overlapping CPU and IO. Output is
+// identical to Writer. Like Writer it's not safe for concurrent use.
+type PipelinedWriter struct {
+	w      io.Writer
+	frames chan []byte
+	done   chan struct{}
+	closed bool
+
+	mu  sync.Mutex
+	err error
+}
+
+// NewPipelinedWriter creates a PipelinedWriter on top of w, Close must be called to flush the last record.
+func NewPipelinedWriter(w io.Writer) *PipelinedWriter {
+	p := &PipelinedWriter{
+		w:      w,
+		frames: make(chan []byte, 1),
+		done:   make(chan struct{}),
+	}
+	go p.flush()
+	return p
+}
+
+func (p *PipelinedWriter) flush() {
+	defer close(p.done)
+	for frame := range p.frames {
+		if p.Err() != nil {
+			continue
+		}
+		if err := writeFull(p.w, frame); err != nil {
+			p.mu.Lock()
+			p.err = err
+			p.mu.Unlock()
+		}
+	}
+}
+
+// Write frames a copy of record and hands it to the background goroutine, it blocks while the previous record
+// is still being written. Write errors of earlier records are returned by later calls and Close.
+func (p *PipelinedWriter) Write(record []byte) (int, error) {
+	if p.closed {
+		return 0, ErrWriterClosed
+	}
+	if err := p.Err(); err != nil {
+		return 0, err
+	}
+	frame := make([]byte, HeaderSize+len(record)+FooterSize)
+	putHeader(frame[:HeaderSize], uint64(len(record)))
+	copy(frame[HeaderSize:], record)
+	putFooter(frame[HeaderSize+len(record):], record)
+	p.frames <- frame
+	return len(record), nil
+}
+
+// Err returns the first error writing to destination.
+func (p *PipelinedWriter) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// Close waits for pending records to be written and returns the first write error. It doesn't close the
+// destination.
+func (p *PipelinedWriter) Close() error {
+	if !p.closed {
+		p.closed = true
+		close(p.frames)
+	}
+	<-p.done
+	return p.Err()
+}
@@ -0,0 +1,90 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestPipelinedWriter(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var records [][]byte
+	for i := 0; i < 100; i++ {
+		record := make([]byte, rng.Intn(1000))
+		rng.Read(record)
+		records = append(records, record)
+	}
+
+	expect := &bytes.Buffer{}
+	w := NewWriter(expect)
+	actual := &bytes.Buffer{}
+	p := NewPipelinedWriter(actual)
+	for _, record := range records {
+		w.Write(record)
+		if n, err := p.Write(record); n != len(record) || err != nil {
+			t.Fatalf("failed writing, n %d, err %v", n, err)
+		}
+		record[0]++
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("failed closing %v", err)
+	}
+	if !bytes.Equal(expect.Bytes(), actual.Bytes()) {
+		t.Errorf("output differs from Writer")
+	}
+	if _, err := p.Write([]byte("x")); err != ErrWriterClosed {
+		t.Errorf("expect ErrWriterClosed, actual %v", err)
+	}
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+func TestPipelinedWriterError(t *testing.T) {
+	errWrite := errors.New("write failed")
+	p := NewPipelinedWriter(failingWriter{errWrite})
+	for i := 0; i < 5; i++ {
+		p.Write([]byte("x"))
+	}
+	if err := p.Close(); err != errWrite {
+		t.Errorf("expect write error, actual %v", err)
+	}
+}
+
+// slowWriter simulates a destination with fixed latency per write.
+type slowWriter struct{}
+
+func (slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(50 * time.Microsecond)
+	return len(p), nil
+}
+
+func benchmarkSlowWriter(b *testing.B, newWriter func(io.Writer) io.Writer) {
+	record := make([]byte, 256*1024)
+	rand.New(rand.NewSource(1)).Read(record)
+	w := newWriter(slowWriter{})
+	b.SetBytes(int64(len(record)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.Write(record)
+	}
+	if c, ok := w.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+func BenchmarkSlowWriter(b *testing.B) {
+	benchmarkSlowWriter(b, func(w io.Writer) io.Writer { return NewWriter(w) })
+}
+
+func BenchmarkSlowPipelinedWriter(b *testing.B) {
+	benchmarkSlowWriter(b, func(w io.Writer) io.Writer { return NewPipelinedWriter(w) })
+}
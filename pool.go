@@ -0,0 +1,27 @@
+package tfrecord
+
+import (
+	"io"
+	"sync"
+)
+
+// WriterPool reuses Writers and their scratch buffers across short-lived writers, its zero value is ready to
+// use and it's safe for concurrent use.
+type WriterPool struct {
+	pool sync.Pool
+}
+
+// Get returns a Writer writing to w, reset to the state of NewWriter(w).
+func (p *WriterPool) Get(w io.Writer) *Writer {
+	if tw, ok := p.pool.Get().(*Writer); ok {
+		tw.reset(w)
+		return tw
+	}
+	return NewWriter(w)
+}
+
+// Put returns tw to the pool, it must not be used after.
+func (p *WriterPool) Put(tw *Writer) {
+	tw.w = nil
+	p.pool.Put(tw)
+}
@@ -0,0 +1,44 @@
+package tfrecord
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestWriterPool(t *testing.T) {
+	var pool WriterPool
+	for i := 0; i < 3; i++ {
+		buf := &bytes.Buffer{}
+		w := pool.Get(buf)
+		for _, r := range []string{"0", "1", "2"} {
+			if _, err := w.Write([]byte(r)); err != nil {
+				t.Fatalf("failed writing %v", err)
+			}
+		}
+		pool.Put(w)
+		if !bytes.Equal(buf.Bytes(), writeTestRecords(t, 3)) {
+			t.Errorf("round %d: unmatched written content", i)
+		}
+	}
+}
+
+func BenchmarkNewWriter(b *testing.B) {
+	record := []byte("record")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w := NewWriter(io.Discard)
+		w.Write(record)
+	}
+}
+
+func BenchmarkWriterPool(b *testing.B) {
+	var pool WriterPool
+	record := []byte("record")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w := pool.Get(io.Discard)
+		w.Write(record)
+		pool.Put(w)
+	}
+}
@@ -0,0 +1,84 @@
+package tfrecord
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrWriterClosed is error returned when writing to a closed writer.
+var ErrWriterClosed = errors.New("TFRecord writer closed")
+
+// QueuedWriter writes records through a bounded queue drained by a background goroutine, so producers are
+// decoupled from the speed of the destination while memory stays bounded: Write blocks when the queue is full.
+// It's safe for concurrent use.
+type QueuedWriter struct {
+	w     *Writer
+	queue chan []byte
+	done  chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+
+	errMu sync.Mutex
+	err   error
+}
+
+// NewQueuedWriter creates a QueuedWriter writing to w with a queue of up to size records.
+func NewQueuedWriter(w *Writer, size int) *QueuedWriter {
+	q := &QueuedWriter{
+		w:     w,
+		queue: make(chan []byte, size),
+		done:  make(chan struct{}),
+	}
+	go q.drain()
+	return q
+}
+
+func (q *QueuedWriter) drain() {
+	defer close(q.done)
+	for record := range q.queue {
+		if q.Err() != nil {
+			continue
+		}
+		if _, err := q.w.Write(record); err != nil {
+			q.errMu.Lock()
+			q.err = err
+			q.errMu.Unlock()
+		}
+	}
+}
+
+// Write queues a copy of record, blocking while the queue is full. It returns the first write error of the
+// background goroutine if any, records queued after that error are dropped.
+func (q *QueuedWriter) Write(record []byte) (int, error) {
+	if err := q.Err(); err != nil {
+		return 0, err
+	}
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return 0, ErrWriterClosed
+	}
+	q.queue <- append([]byte(nil), record...)
+	return len(record), nil
+}
+
+// Err returns the first error writing to the underlying Writer.
+func (q *QueuedWriter) Err() error {
+	q.errMu.Lock()
+	defer q.errMu.Unlock()
+	return q.err
+}
+
+// Close waits for all queued records to be written and returns the first write error. It doesn't close the
+// underlying destination.
+func (q *QueuedWriter) Close() error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.queue)
+	}
+	q.mu.Unlock()
+	<-q.done
+	return q.Err()
+}
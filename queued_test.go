@@ -0,0 +1,80 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// blockingWriter blocks every write until it receives from release.
+type blockingWriter struct {
+	bytes.Buffer
+	release chan struct{}
+	err     error
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	if w.err != nil {
+		return 0, w.err
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestQueuedWriter(t *testing.T) {
+	dst := &blockingWriter{release: make(chan struct{})}
+	q := NewQueuedWriter(NewWriter(dst), 2)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	written := make(chan int, 10)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			record := []byte(strconv.Itoa(i))
+			if _, err := q.Write(record); err != nil {
+				t.Errorf("failed writing %v", err)
+			}
+			record[0] = 'x'
+			written <- i
+		}
+	}()
+	// one record held by the blocked background write, two in the queue, one blocked producer.
+	for i := 0; i < 3; i++ {
+		<-written
+	}
+	select {
+	case i := <-written:
+		t.Fatalf("write %d not blocked by full queue", i)
+	default:
+	}
+	close(dst.release)
+	wg.Wait()
+	if err := q.Close(); err != nil {
+		t.Fatalf("failed closing %v", err)
+	}
+	if !bytes.Equal(dst.Bytes(), writeTestRecords(t, 10)) {
+		t.Errorf("unmatched written content")
+	}
+	if _, err := q.Write([]byte("x")); err != ErrWriterClosed {
+		t.Errorf("expect ErrWriterClosed, actual %v", err)
+	}
+}
+
+func TestQueuedWriterError(t *testing.T) {
+	errWrite := errors.New("write failed")
+	dst := &blockingWriter{release: make(chan struct{}), err: errWrite}
+	close(dst.release)
+	q := NewQueuedWriter(NewWriter(dst), 1)
+	for i := 0; i < 5; i++ {
+		q.Write([]byte("x"))
+	}
+	if err := q.Close(); err != errWrite {
+		t.Errorf("expect write error, actual %v", err)
+	}
+	if _, err := q.Write([]byte("x")); err != errWrite {
+		t.Errorf("expect write error after failure, actual %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package tfrecord
+
+import "iter"
+
+// All returns an iterator of remaining records for range-over-func loops, calling Next until it returns false.
+// Each record aliases the iterator buffer and is only valid during its loop iteration, copy it to retain it.
+// Check Err after the loop. Breaking out of the loop leaves the iterator after the last yielded record.
+func (it *Iterator) All() iter.Seq[[]byte] {
+	return func(yield func([]byte) bool) {
+		for it.Next() {
+			if !yield(it.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// All2 is All yielding records with their index in the stream, counting records before the iterator's
+// starting State, if it's resumed.
+func (it *Iterator) All2() iter.Seq2[int, []byte] {
+	return func(yield func(int, []byte) bool) {
+		for it.Next() {
+			if !yield(int(it.ordinal-1), it.Value()) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	data := writeTestRecords(t, 10)
+	it := NewIterator(bytes.NewReader(data), 16, true)
+	n := 0
+	for record := range it.All() {
+		if string(record) != strconv.Itoa(n) {
+			t.Errorf("expect record %d, actual %q", n, record)
+		}
+		n++
+	}
+	if n != 10 || it.Err() != nil {
+		t.Errorf("expect 10 records, actual %d, err %v", n, it.Err())
+	}
+
+	it = NewIterator(bytes.NewReader(data), 16, true)
+	for i, record := range it.All2() {
+		if string(record) != strconv.Itoa(i) {
+			t.Errorf("expect record %d, actual %q", i, record)
+		}
+		if i == 4 {
+			break
+		}
+	}
+	for i := range it.All2() {
+		if i != 5 {
+			t.Errorf("expect to continue from record 5, actual %d", i)
+		}
+		break
+	}
+
+	data[3*FrameSize(1)+HeaderSize]++
+	it = NewIterator(bytes.NewReader(data), 16, true)
+	n = 0
+	for range it.All() {
+		n++
+	}
+	if n != 3 || !errors.Is(it.Err(), ErrChecksum) {
+		t.Errorf("expect checksum error after 3 records, actual %d, err %v", n, it.Err())
+	}
+}
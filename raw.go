@@ -0,0 +1,47 @@
+package tfrecord
+
+import (
+	"encoding/binary"
+)
+
+// WithRawWrites makes the writer write raw frames: the 8 bytes record length followed by payload, with no
+// CRC at all. It's a fast path for trusted local IPC trading integrity for speed, raw streams are NOT
+// TFRecords and can only be read with WithRawReads.
+func WithRawWrites() WriterOption {
+	return func(w *Writer) {
+		w.raw = true
+	}
+}
+
+// putHeader writes header of a record of n bytes into b and returns it, it's shorter than HeaderSize for
+// raw frames.
+func (w *Writer) putHeader(b []byte, n uint64) []byte {
+	if w.raw {
+		binary.LittleEndian.PutUint64(b, n)
+		return b[:LengthSize]
+	}
+	putHeader(b[:HeaderSize], n)
+	return b[:HeaderSize]
+}
+
+func (w *Writer) headerLen() int {
+	if w.raw {
+		return LengthSize
+	}
+	return HeaderSize
+}
+
+// WithRawReads makes the iterator read raw frames written with WithRawWrites, no CRC is checked regardless of
+// checkDataCRC. A corrupt length can't be detected, pair it with WithMaxTotalBuffer to bound allocation.
+func WithRawReads() Option {
+	return func(it *Iterator) {
+		it.raw = true
+	}
+}
+
+func (it *Iterator) headerLen() int {
+	if it.raw {
+		return LengthSize
+	}
+	return HeaderSize
+}
@@ -0,0 +1,62 @@
+package tfrecord
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRawFraming(t *testing.T) {
+	records := []string{"a", "", strings.Repeat("x", singleWriteMax+1)}
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf, WithRawWrites())
+	for _, r := range records[:2] {
+		w.Write([]byte(r))
+	}
+	w.WriteFrom(strings.NewReader(records[2]), uint64(len(records[2])))
+	if expect := 3*LengthSize + 2 + singleWriteMax; buf.Len() != expect {
+		t.Errorf("expect %d bytes, actual %d", expect, buf.Len())
+	}
+	it := NewIterator(bytes.NewReader(buf.Bytes()), 16, true, WithRawReads())
+	for i, r := range records {
+		if !it.Next() || string(it.Value()) != r {
+			t.Fatalf("expect record %d, actual %v", i, it.Err())
+		}
+	}
+	if it.Next() || it.Err() != nil {
+		t.Errorf("expect clean end, actual %v", it.Err())
+	}
+	if s := it.State(); s.Offset != int64(buf.Len()) {
+		t.Errorf("expect offset %d, actual %d", buf.Len(), s.Offset)
+	}
+}
+
+func benchmarkRead(b *testing.B, data []byte, opts ...Option) {
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		it := NewIterator(bytes.NewReader(data), 1024, true, opts...)
+		for _, ok := it.NextReuse(); ok; _, ok = it.NextReuse() {
+		}
+	}
+}
+
+func BenchmarkReadSmall(b *testing.B) {
+	sizes := make([]int, 1000)
+	for i := range sizes {
+		sizes[i] = 100
+	}
+	benchmarkRead(b, writeSizedRecords(b, sizes...))
+}
+
+func BenchmarkReadSmallRaw(b *testing.B) {
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf, WithRawWrites())
+	for i := 0; i < 1000; i++ {
+		w.Write(bytes.Repeat([]byte("x"), 100))
+	}
+	benchmarkRead(b, buf.Bytes(), WithRawReads())
+}
+
+func BenchmarkWriteSmallRaw(b *testing.B) {
+	benchmarkWrite(b, 100, WithRawWrites())
+}
@@ -0,0 +1,92 @@
+package tfrecord
+
+import (
+	"bufio"
+	"fmt"
+)
+
+// minReadaheadBufSize is the minimal size of the buffered reader used for readahead.
+const minReadaheadBufSize = 4096
+
+// WithReadahead makes the iterator read up to n frames ahead in one go, through a buffered reader sized for
+// n frames of bufSize, so a sequential scan over high-latency storage issues fewer and larger reads. Unlike
+// a prefetching goroutine everything happens in the calling goroutine. Buffered records are independent copies,
+// so Value stays valid after Next, at the cost of an allocation per record. NextReuse copies them into the
+// iterator buffer and enforces its size as without readahead, but the allocation per record remains.
+func WithReadahead(n int) Option {
+	return func(it *Iterator) {
+		it.readahead = n
+	}
+}
+
+// WithReaderBuffer wraps the underlying reader in a bufio.Reader of size bytes. Readers that are already a
+// *bufio.Reader are used directly, never buffered twice. When combined with WithReadahead, the buffer set here
+// is the one readahead reads through, instead of the one readahead would size for itself.
+func WithReaderBuffer(size int) Option {
+	return func(it *Iterator) {
+		it.readerBuf = size
+	}
+}
+
+// initReadahead sets up buffering of the underlying reader and the readahead buffer.
+func (it *Iterator) initReadahead() {
+	if _, ok := it.in.(*bufio.Reader); !ok && it.readerBuf > 0 {
+		it.in = bufio.NewReaderSize(it.in, it.readerBuf)
+	}
+	if it.readahead <= 0 {
+		return
+	}
+	it.aheadBuf = make([]frame, 0, it.readahead)
+	if _, ok := it.in.(*bufio.Reader); ok {
+		return
+	}
+	size := it.readahead * (len(it.preBuf) + HeaderSize + FooterSize)
+	if size < minReadaheadBufSize {
+		size = minReadaheadBufSize
+	}
+	it.in = bufio.NewReaderSize(it.in, size)
+}
+
+// nextBuffered returns next frame from readahead buffer, refilling it when empty.
+func (it *Iterator) nextBuffered() (frame, error) {
+	if len(it.ahead) == 0 {
+		if it.aheadErr != nil {
+			return frame{}, it.aheadErr
+		}
+		it.ahead = it.aheadBuf[:0]
+		for len(it.ahead) < it.readahead {
+			f, err := it.readFrame(false, true)
+			if err != nil {
+				it.aheadErr = err
+				break
+			}
+			it.ahead = append(it.ahead, f)
+		}
+		if len(it.ahead) == 0 {
+			return frame{}, it.aheadErr
+		}
+	}
+	f := it.ahead[0]
+	it.ahead[0] = frame{}
+	it.ahead = it.ahead[1:]
+	return f, nil
+}
+
+// reuseBuffered copies a readahead frame into preBuf for NextReuse, so the record aliases the iterator buffer
+// and the buffer size is enforced like without readahead.
+func (it *Iterator) reuseBuffered(f frame) (frame, error) {
+	n := len(f.record)
+	if n == 0 {
+		return f, nil
+	}
+	if n > len(it.preBuf) {
+		if !it.adaptive {
+			// the frame is consumed already, ClearErr can move on to the next one.
+			it.clearable = clearAtBoundary
+			return frame{}, fmt.Errorf("record of %d bytes with buffer of %d bytes: %w", n, len(it.preBuf), ErrBufferTooSmall)
+		}
+		it.growBuf(n)
+	}
+	f.record, f.owned = it.preBuf[:copy(it.preBuf, f.record)], false
+	return f, nil
+}
@@ -0,0 +1,131 @@
+package tfrecord
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// countingReader counts Read calls.
+type countingReader struct {
+	r     io.Reader
+	reads int
+	delay time.Duration
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	r.reads++
+	time.Sleep(r.delay)
+	return r.r.Read(p)
+}
+
+func TestReadahead(t *testing.T) {
+	data := writeTestRecords(t, 100)
+	r := &countingReader{r: bytes.NewReader(data)}
+	it := NewIterator(r, 16, true, WithReadahead(10))
+	var values [][]byte
+	for it.Next() {
+		values = append(values, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("read error %v", err)
+	}
+	if len(values) != 100 {
+		t.Fatalf("expect 100 records, actual %d", len(values))
+	}
+	for i, v := range values {
+		if string(v) != strconv.Itoa(i) {
+			t.Fatalf("record %d changed to %s after later reads", i, v)
+		}
+	}
+	if r.reads > 5 {
+		t.Errorf("expect batched reads, actual %d reads", r.reads)
+	}
+	if s := it.State(); s.Offset != int64(len(data)) || s.Ordinal != 100 {
+		t.Errorf("unexpected state %+v", s)
+	}
+}
+
+func TestReadaheadError(t *testing.T) {
+	data := writeTestRecords(t, 10)
+	frameSize := HeaderSize + 1 + FooterSize
+	data[5*frameSize+HeaderSize]++
+	it := NewIterator(bytes.NewReader(data), 16, true, WithReadahead(4))
+	n := 0
+	for it.Next() {
+		n++
+	}
+	var re *RecordError
+	if n != 5 || !errors.As(it.Err(), &re) || re.Offset != int64(5*frameSize) {
+		t.Errorf("expect error after 5 records at offset %d, actual %d records, err %v", 5*frameSize, n, it.Err())
+	}
+}
+
+func TestReadaheadReuse(t *testing.T) {
+	data := writeSizedRecords(t, 3, 20)
+	it := NewIterator(bytes.NewReader(data), 4, true, WithReadahead(2))
+	rec, ok := it.NextReuse()
+	if !ok || len(rec) != 3 || &rec[0] != &it.preBuf[0] {
+		t.Fatalf("expect record aliasing iterator buffer, actual %q, %v", rec, it.Err())
+	}
+	if rec, ok := it.NextReuse(); ok || !errors.Is(it.Err(), ErrBufferTooSmall) {
+		t.Errorf("expect ErrBufferTooSmall, actual %q, %v", rec, it.Err())
+	}
+
+	it = NewIterator(bytes.NewReader(data), 0, true, WithReadahead(2))
+	n := 0
+	for rec, ok := it.NextReuse(); ok; rec, ok = it.NextReuse() {
+		if &rec[0] != &it.preBuf[0] {
+			t.Errorf("expect record %d aliasing iterator buffer", n)
+		}
+		n++
+	}
+	if it.Err() != nil || n != 2 {
+		t.Errorf("expect 2 records with adaptive buffer, actual %d, %v", n, it.Err())
+	}
+}
+
+func TestReaderBuffer(t *testing.T) {
+	data := writeTestRecords(t, 100)
+	r := &countingReader{r: bytes.NewReader(data)}
+	it := NewIterator(r, 16, true, WithReaderBuffer(len(data)))
+	n := 0
+	for it.Next() {
+		n++
+	}
+	if it.Err() != nil || n != 100 {
+		t.Fatalf("expect 100 records, actual %d, %v", n, it.Err())
+	}
+	if r.reads > 2 {
+		t.Errorf("expect buffered reads, actual %d reads", r.reads)
+	}
+
+	br := bufio.NewReader(bytes.NewReader(data))
+	for _, opts := range [][]Option{nil, {WithReaderBuffer(16)}, {WithReadahead(4)}} {
+		if it := NewIterator(br, 16, true, opts...); it.in != br {
+			t.Errorf("expect *bufio.Reader used directly with %d options", len(opts))
+		}
+	}
+}
+
+func benchmarkLatencyReader(b *testing.B, opts ...Option) {
+	data := writeSizedRecords(b, make([]int, 1000)...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		it := NewIterator(&countingReader{r: bytes.NewReader(data), delay: 20 * time.Microsecond}, 64, true, opts...)
+		for it.Next() {
+		}
+	}
+}
+
+func BenchmarkLatencyReader(b *testing.B) {
+	benchmarkLatencyReader(b)
+}
+
+func BenchmarkLatencyReaderReadahead(b *testing.B) {
+	benchmarkLatencyReader(b, WithReadahead(64))
+}
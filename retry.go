@@ -0,0 +1,96 @@
+package tfrecord
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"time"
+)
+
+// WithRetry makes the iterator retry reads from the underlying reader failing with a retryable error, up to
+// attempts times per read with backoff in between. Data already read is kept, a retried read continues at the
+// same position. Errors are classified by WithRetryableError, DefaultRetryable by default.
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(it *Iterator) {
+		it.retryAttempts, it.retryBackoff = attempts, backoff
+	}
+}
+
+// WithRetryableError sets fn to decide which read errors WithRetry retries. Context cancellation, errors
+// matching context.Canceled or context.DeadlineExceeded, is never retried whatever fn returns.
+func WithRetryableError(fn func(error) bool) Option {
+	return func(it *Iterator) {
+		it.retryable = fn
+	}
+}
+
+// DefaultRetryable reports whether err is transient: a net.Error that is a timeout, or any error with a
+// Temporary() bool method returning true.
+func DefaultRetryable(err error) bool {
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+	var te interface{ Temporary() bool }
+	return errors.As(err, &te) && te.Temporary()
+}
+
+func (it *Iterator) initRetry() {
+	if it.retryAttempts <= 0 {
+		return
+	}
+	retryable := it.retryable
+	if retryable == nil {
+		retryable = DefaultRetryable
+	}
+	it.in = &retryReader{r: it.in, attempts: it.retryAttempts, backoff: it.retryBackoff, retryable: retryable}
+}
+
+// retryReader retries failed reads of r classified retryable.
+type retryReader struct {
+	r         io.Reader
+	attempts  int
+	backoff   time.Duration
+	retryable func(error) bool
+}
+
+func (r *retryReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	for i := 0; i < r.attempts && err != nil && r.shouldRetry(err); i++ {
+		if n > 0 {
+			// deliver what's read, next Read retries.
+			return n, nil
+		}
+		time.Sleep(r.backoff)
+		n, err = r.r.Read(p)
+	}
+	return n, err
+}
+
+func (r *retryReader) shouldRetry(err error) bool {
+	return err != io.EOF && !isContextErr(err) && r.retryable(err)
+}
+
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// WithResumableReads makes a read failing midway a frame with a transient error, classified like WithRetry
+// does, resumable: Next returns false and Err reports the error, but calling Next again continues the same
+// frame from where the read stopped, without losing position. Unlike WithRetry the caller decides when and
+// whether to retry. Other errors stop iteration as usual.
+func WithResumableReads() Option {
+	return func(it *Iterator) {
+		it.resumable = true
+	}
+}
+
+// isTransient reports whether err is retryable, following WithRetryableError.
+func (it *Iterator) isTransient(err error) bool {
+	retryable := it.retryable
+	if retryable == nil {
+		retryable = DefaultRetryable
+	}
+	return !isContextErr(err) && retryable(err)
+}
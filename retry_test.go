@@ -0,0 +1,111 @@
+package tfrecord
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"testing"
+)
+
+// flakyReader fails every other Read with err, reading at most max bytes at once.
+type flakyReader struct {
+	r     io.Reader
+	err   error
+	max   int
+	calls int
+}
+
+func (r *flakyReader) Read(p []byte) (int, error) {
+	r.calls++
+	if r.calls%2 == 0 {
+		return 0, r.err
+	}
+	if len(p) > r.max {
+		p = p[:r.max]
+	}
+	return r.r.Read(p)
+}
+
+func TestRetry(t *testing.T) {
+	data := writeTestRecords(t, 20)
+	r := &flakyReader{r: bytes.NewReader(data), err: os.ErrDeadlineExceeded, max: 5}
+	it := NewIterator(r, 16, true, WithRetry(1, 0))
+	for i := 0; i < 20; i++ {
+		if !it.Next() || string(it.Value()) != strconv.Itoa(i) {
+			t.Fatalf("expect record %d, actual %q, %v", i, it.Value(), it.Err())
+		}
+	}
+	if it.Next() || it.Err() != nil {
+		t.Errorf("expect clean end, actual %v", it.Err())
+	}
+
+	errFlaky := errors.New("flaky")
+	for _, c := range []struct {
+		name      string
+		err       error
+		retryable func(error) bool
+		retried   bool
+	}{
+		{"default not retryable", errFlaky, nil, false},
+		{"custom retryable", errFlaky, func(err error) bool { return err == errFlaky }, true},
+		{"context never retried", context.Canceled, func(error) bool { return true }, false},
+	} {
+		r := &flakyReader{r: bytes.NewReader(data), err: c.err, max: 5}
+		it := NewIterator(r, 16, true, WithRetry(1, 0), WithRetryableError(c.retryable))
+		n := 0
+		for it.Next() {
+			n++
+		}
+		if retried := it.Err() == nil && n == 20; retried != c.retried {
+			t.Errorf("%s: expect retried %v, actual %d records, %v", c.name, c.retried, n, it.Err())
+		}
+	}
+}
+
+func TestMultiIteratorRecoverableError(t *testing.T) {
+	bad := writeTestRecords(t, 3)
+	bad[HeaderSize]++
+	_, srcs := newCloseCounters(bad, writeTestRecords(t, 3))
+	m := NewMultiIterator(srcs, 16, true)
+	m.SkipFailedSources(nil)
+	m.SetRecoverableError(func(err error) bool { return !errors.Is(err, ErrChecksum) })
+	for m.Next() {
+	}
+	if !errors.Is(m.Err(), ErrChecksum) || len(m.FailedSources()) != 0 {
+		t.Errorf("expect unrecoverable ErrChecksum, actual %v, failed %v", m.Err(), m.FailedSources())
+	}
+}
+
+func TestResumableReads(t *testing.T) {
+	data := writeSizedRecords(t, 3, 40, 0, 5)
+	r := &flakyReader{r: bytes.NewReader(data), err: os.ErrDeadlineExceeded, max: 7}
+	it := NewIterator(r, 16, true, WithResumableReads())
+	var sizes []int
+	failures := 0
+	for failures < 100 {
+		if it.Next() {
+			sizes = append(sizes, len(it.Value()))
+			continue
+		}
+		if !errors.Is(it.Err(), os.ErrDeadlineExceeded) {
+			break
+		}
+		failures++
+	}
+	if it.Err() != nil || len(sizes) != 4 || sizes[1] != 40 || sizes[3] != 5 {
+		t.Errorf("unexpected records %v, %v", sizes, it.Err())
+	}
+	if failures == 0 {
+		t.Errorf("expect transient failures")
+	}
+
+	r = &flakyReader{r: bytes.NewReader(data), err: errors.New("permanent"), max: 7}
+	it = NewIterator(r, 16, true, WithResumableReads())
+	it.Next()
+	if it.Next() || it.Next() || it.Err() == nil {
+		t.Errorf("expect permanent error to stop iteration")
+	}
+}
@@ -0,0 +1,61 @@
+package tfrecord
+
+import (
+	"fmt"
+	"io"
+)
+
+// ReverseIterator iterates records from last to first, reading them at locations of an index.
+type ReverseIterator struct {
+	r     *IndexedReader
+	index []RecordLocation
+	next  int
+	value []byte
+	err   error
+}
+
+// NewReverseIterator creates a ReverseIterator over records from current position of r. Reversing needs
+// random access, r must be an io.ReadSeeker, an error is returned for plain streams. An index of r is built
+// first by scanning record headers, use NewReverseIteratorFromIndex when an index is at hand.
+func NewReverseIterator(r io.Reader, checkDataCRC bool) (*ReverseIterator, error) {
+	rs, ok := r.(io.ReadSeeker)
+	if !ok {
+		return nil, fmt.Errorf("reverse iteration needs an io.ReadSeeker, got %T", r)
+	}
+	ra, err := newReaderAt(rs)
+	if err != nil {
+		return nil, err
+	}
+	index, err := BuildIndex(rs)
+	if err != nil {
+		return nil, err
+	}
+	return NewReverseIteratorFromIndex(ra, index, checkDataCRC), nil
+}
+
+// NewReverseIteratorFromIndex creates a ReverseIterator reading records at index from r, from the last
+// location to the first.
+func NewReverseIteratorFromIndex(r io.ReaderAt, index []RecordLocation, checkDataCRC bool) *ReverseIterator {
+	return &ReverseIterator{r: NewIndexedReader(r, checkDataCRC), index: index, next: len(index) - 1}
+}
+
+// Next moves to previous record
+func (it *ReverseIterator) Next() bool {
+	it.value = nil
+	if it.err != nil || it.next < 0 {
+		return false
+	}
+	it.value, it.err = it.r.ReadAt(it.index[it.next])
+	it.next--
+	return it.err == nil
+}
+
+// Value returns the current record, it's newly allocated for each record
+func (it *ReverseIterator) Value() []byte {
+	return it.value
+}
+
+// Err returns any error stopping Next()
+func (it *ReverseIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,38 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestReverseIterator(t *testing.T) {
+	data := writeTestRecords(t, 10)
+	it, err := NewReverseIterator(bytes.NewReader(data), true)
+	if err != nil {
+		t.Fatalf("create error %v", err)
+	}
+	for i := 9; i >= 0; i-- {
+		if !it.Next() || string(it.Value()) != strconv.Itoa(i) {
+			t.Fatalf("expect record %d, actual %q, %v", i, it.Value(), it.Err())
+		}
+	}
+	if it.Next() || it.Err() != nil {
+		t.Errorf("expect clean end, actual %v", it.Err())
+	}
+
+	if _, err := NewReverseIterator(bytes.NewBuffer(data), true); err == nil {
+		t.Errorf("expect error for non seekable reader")
+	}
+
+	data[HeaderSize]++
+	it, _ = NewReverseIterator(bytes.NewReader(data), true)
+	n := 0
+	for it.Next() {
+		n++
+	}
+	if n != 9 || !errors.Is(it.Err(), ErrChecksum) {
+		t.Errorf("expect ErrChecksum after 9 records, actual %d, %v", n, it.Err())
+	}
+}
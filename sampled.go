@@ -0,0 +1,36 @@
+package tfrecord
+
+import (
+	"math/rand"
+)
+
+// WithSampledCRC makes the iterator check data CRC of a random fraction rate of records only, drawn from rng,
+// for a cheap probabilistic integrity signal over huge files. It overrides checkDataCRC, failures are handled
+// the same as checkDataCRC, stopping iteration unless WithCorruptRecords is set. SampledCRCStats reports the
+// counts. NextStream still follows checkDataCRC.
+func WithSampledCRC(rate float64, rng *rand.Rand) Option {
+	return func(it *Iterator) {
+		it.sampleRate, it.sampleRNG = rate, rng
+	}
+}
+
+// shouldCheckCRC reports whether data CRC of current record should be checked.
+func (it *Iterator) shouldCheckCRC() bool {
+	if it.sampleRNG == nil {
+		if it.checkDataCRC {
+			it.sampled++
+		}
+		return it.checkDataCRC
+	}
+	if it.sampleRNG.Float64() >= it.sampleRate {
+		return false
+	}
+	it.sampled++
+	return true
+}
+
+// SampledCRCStats returns number of records whose data CRC was checked and number of them failing, see
+// WithSampledCRC. Without sampling, checked counts all records when checkDataCRC is true.
+func (it *Iterator) SampledCRCStats() (checked, failed int) {
+	return it.sampled, it.sampleFailed
+}
@@ -0,0 +1,36 @@
+package tfrecord
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestSampledCRC(t *testing.T) {
+	data := writeTestRecords(t, 1000)
+	// corrupt data of every record.
+	for offset := 0; offset < len(data); {
+		length := int(data[offset])
+		data[offset+HeaderSize]++
+		offset += int(FrameSize(length))
+	}
+	it := NewIterator(bytes.NewReader(data), 16, false, WithSampledCRC(0.1, rand.New(rand.NewSource(1))), WithCorruptRecords(true))
+	n := 0
+	for it.Next() {
+		n++
+	}
+	checked, failed := it.SampledCRCStats()
+	if n != 1000 || it.Err() != nil {
+		t.Fatalf("expect 1000 records, actual %d, %v", n, it.Err())
+	}
+	if checked < 50 || checked > 150 || failed != checked {
+		t.Errorf("expect about 100 checked records all failing, actual %d checked, %d failed", checked, failed)
+	}
+
+	it = NewIterator(bytes.NewReader(writeTestRecords(t, 10)), 16, true)
+	for it.Next() {
+	}
+	if checked, failed := it.SampledCRCStats(); checked != 10 || failed != 0 {
+		t.Errorf("expect all records checked without sampling, actual %d, %d", checked, failed)
+	}
+}
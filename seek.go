@@ -0,0 +1,61 @@
+package tfrecord
+
+import (
+	"fmt"
+	"io"
+)
+
+// SeekToPayloadOffset advances it to the record starting at payloadOffset bytes of payload after its current
+// position, the sum of payload lengths of records skipped, for external indexes keyed on logical content offset.
+// Offsets in State and errors are frame offsets instead, framing bytes included. Only headers are read,
+// payloads are skipped by seeking, so the underlying reader must be an io.ReadSeeker and the iterator must not
+// buffer ahead, like with WithReaderBuffer, WithReadahead or WithCompression. It's an error if payloadOffset
+// falls in the middle of a record, the iterator is then left at the start of that record, or beyond end of
+// stream.
+func SeekToPayloadOffset(it *Iterator, payloadOffset int64) error {
+	s, ok := it.r.(io.Seeker)
+	if !ok || it.in != it.r {
+		return fmt.Errorf("seeking by payload offset needs an unbuffered io.ReadSeeker, got %T", it.in)
+	}
+	if it.err != nil {
+		return it.err
+	}
+	if payloadOffset < 0 {
+		return fmt.Errorf("negative payload offset %d", payloadOffset)
+	}
+	if !it.drainStream() {
+		return it.err
+	}
+	it.value, it.valueOwned = nil, false
+	var pos int64
+	for pos < payloadOffset {
+		offset, length, err := it.readHeader()
+		if err == io.EOF {
+			return fmt.Errorf("payload offset %d beyond end of stream at payload offset %d: %w", payloadOffset, pos, io.ErrUnexpectedEOF)
+		}
+		if err != nil {
+			it.err = err
+			return err
+		}
+		it.part = partialFrame{}
+		if length > uint64(payloadOffset-pos) {
+			if _, err := s.Seek(-int64(it.headerLen()), io.SeekCurrent); err != nil {
+				it.err = err
+				return err
+			}
+			it.readOffset = offset
+			return fmt.Errorf("payload offset %d in the middle of record at offset %d, covering payload offset [%d, %d)",
+				payloadOffset, offset, pos, pos+int64(length))
+		}
+		rest := int64(length) + int64(it.footerLen())
+		if _, err := s.Seek(rest, io.SeekCurrent); err != nil {
+			it.err = err
+			return err
+		}
+		it.readOffset += rest
+		it.offset = it.readOffset
+		it.ordinal++
+		pos += int64(length)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package tfrecord
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestSeekToPayloadOffset(t *testing.T) {
+	data := writeSizedRecords(t, 3, 0, 5, 7)
+	for _, c := range []struct {
+		payloadOffset int64
+		expect        int
+	}{{0, 3}, {3, 0}, {8, 7}} {
+		it := NewIterator(bytes.NewReader(data), 16, true)
+		if err := SeekToPayloadOffset(it, c.payloadOffset); err != nil {
+			t.Fatalf("seek to %d error %v", c.payloadOffset, err)
+		}
+		if !it.Next() || len(it.Value()) != c.expect {
+			t.Errorf("expect %d bytes record at payload offset %d, actual %d, err %v", c.expect, c.payloadOffset, len(it.Value()), it.Err())
+		}
+	}
+
+	it := NewIterator(bytes.NewReader(data), 16, true)
+	if err := SeekToPayloadOffset(it, 15); err != nil {
+		t.Fatalf("seek to end error %v", err)
+	}
+	if it.Next() || it.Err() != nil {
+		t.Errorf("expect clean end after seeking to end, err %v", it.Err())
+	}
+	if s := it.State(); s.Offset != int64(len(data)) || s.Ordinal != 4 {
+		t.Errorf("unexpected state %+v", s)
+	}
+}
+
+func TestSeekToPayloadOffsetError(t *testing.T) {
+	data := writeSizedRecords(t, 3, 5, 7)
+	it := NewIterator(bytes.NewReader(data), 16, true)
+	if err := SeekToPayloadOffset(it, 5); err == nil {
+		t.Fatalf("expect error seeking into middle of record")
+	}
+	if !it.Next() || len(it.Value()) != 5 || it.State().Offset != FrameSize(3)+FrameSize(5) {
+		t.Errorf("expect iterator at start of record containing offset, err %v", it.Err())
+	}
+
+	it = NewIterator(bytes.NewReader(data), 16, true)
+	if err := SeekToPayloadOffset(it, 16); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expect error seeking beyond end, actual %v", err)
+	}
+	it = NewIterator(bufio.NewReader(bytes.NewReader(data)), 16, true)
+	if err := SeekToPayloadOffset(it, 3); err == nil {
+		t.Errorf("expect error seeking a non-seekable reader")
+	}
+}
@@ -0,0 +1,46 @@
+package tfrecord
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+func TestSequencePrefix(t *testing.T) {
+	large := strings.Repeat("x", singleWriteMax)
+	records := []string{"a", "", large, "b"}
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf, WithSequencePrefix())
+	for _, r := range records[:3] {
+		if _, err := w.Write([]byte(r)); err != nil {
+			t.Fatalf("write error %v", err)
+		}
+	}
+	if _, err := w.WriteFrom(strings.NewReader(records[3]), 1); err != nil {
+		t.Fatalf("write error %v", err)
+	}
+
+	it := NewIterator(bytes.NewReader(buf.Bytes()), 16, true, WithSequenceNumbers())
+	for i, r := range records {
+		if !it.Next() || string(it.Value()) != r || it.Sequence() != uint64(i) {
+			t.Fatalf("expect record %d, actual sequence %d, %v", i, it.Sequence(), it.Err())
+		}
+	}
+	if it.Next() || it.Err() != nil {
+		t.Errorf("expect clean end, actual %v", it.Err())
+	}
+
+	// plain readers see the prefix as part of payload.
+	it = NewIterator(bytes.NewReader(buf.Bytes()), 16, true)
+	for i, r := range records {
+		if !it.Next() || binary.LittleEndian.Uint64(it.Value()) != uint64(i) || string(it.Value()[8:]) != r {
+			t.Fatalf("unexpected raw record %d, %v", i, it.Err())
+		}
+	}
+
+	it = NewIterator(bytes.NewReader(writeTestRecords(t, 1)), 16, true, WithSequenceNumbers())
+	if it.Next() || it.Err() == nil {
+		t.Errorf("expect error for record without prefix")
+	}
+}
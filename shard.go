@@ -0,0 +1,99 @@
+package tfrecord
+
+import (
+	"io"
+)
+
+// ShardInfo describes a shard written by ShardWriter.
+type ShardInfo struct {
+	Index   int
+	Records int
+	// Bytes is the framed size of the shard.
+	Bytes int64
+}
+
+// ShardWriter writes records into a sequence of shards, rolling to a new shard when the current one would
+// exceed maxBytes. A shard always holds at least one record, so a record larger than maxBytes gets a shard
+// of its own.
+type ShardWriter struct {
+	create   func(index int) (io.WriteCloser, error)
+	maxBytes int64
+
+	cur      io.WriteCloser
+	w        *Writer
+	manifest []ShardInfo
+	err      error
+}
+
+// NewShardWriter creates a ShardWriter, create is called with shard index starting from 0 to open each
+// shard, ShardWriter closes it when rolling to next one or on Close.
+func NewShardWriter(create func(index int) (io.WriteCloser, error), maxBytes int64) *ShardWriter {
+	return &ShardWriter{create: create, maxBytes: maxBytes}
+}
+
+// Write writes a record to current shard, opening a new shard first if needed.
+func (s *ShardWriter) Write(record []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	size := FrameSize(len(record))
+	if s.cur == nil || s.shard().Bytes+size > s.maxBytes && s.shard().Records > 0 {
+		if err := s.roll(); err != nil {
+			s.err = err
+			return 0, err
+		}
+	}
+	n, err := s.w.Write(record)
+	if err != nil {
+		s.err = err
+		return n, err
+	}
+	info := s.shard()
+	info.Records++
+	info.Bytes += size
+	return n, nil
+}
+
+func (s *ShardWriter) shard() *ShardInfo {
+	return &s.manifest[len(s.manifest)-1]
+}
+
+func (s *ShardWriter) roll() error {
+	if s.cur != nil {
+		err := s.cur.Close()
+		s.cur = nil
+		if err != nil {
+			return err
+		}
+	}
+	index := len(s.manifest)
+	f, err := s.create(index)
+	if err != nil {
+		return err
+	}
+	s.cur, s.w = f, NewWriter(f)
+	s.manifest = append(s.manifest, ShardInfo{Index: index})
+	return nil
+}
+
+// Close closes current shard, no more records can be written after Close.
+func (s *ShardWriter) Close() error {
+	if s.err == ErrWriterClosed {
+		return nil
+	}
+	var err error
+	if s.cur != nil {
+		err = s.cur.Close()
+		s.cur = nil
+	}
+	if s.err == nil {
+		s.err = ErrWriterClosed
+	}
+	return err
+}
+
+// Manifest returns metadata of shards written so far, including current, possibly partial, shard. It's
+// meant to be called after Close to register produced shards without re-scanning them.
+func (s *ShardWriter) Manifest() []ShardInfo {
+	return append([]ShardInfo(nil), s.manifest...)
+}
@@ -0,0 +1,62 @@
+package tfrecord
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+type bufferCloser struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *bufferCloser) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestShardWriterManifest(t *testing.T) {
+	var shards []*bufferCloser
+	s := NewShardWriter(func(index int) (io.WriteCloser, error) {
+		shards = append(shards, &bufferCloser{})
+		return shards[index], nil
+	}, 3*(HeaderSize+10+FooterSize))
+	for i := 0; i < 7; i++ {
+		if _, err := s.Write(bytes.Repeat([]byte("x"), 10)); err != nil {
+			t.Fatalf("write error %v", err)
+		}
+	}
+	if _, err := s.Write([]byte(strings.Repeat("y", 100))); err != nil {
+		t.Fatalf("write error %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("close error %v", err)
+	}
+	if _, err := s.Write([]byte("x")); err != ErrWriterClosed {
+		t.Errorf("expect ErrWriterClosed, actual %v", err)
+	}
+	manifest := s.Manifest()
+	expect := []ShardInfo{
+		{0, 3, 3 * (HeaderSize + 10 + FooterSize)},
+		{1, 3, 3 * (HeaderSize + 10 + FooterSize)},
+		{2, 1, HeaderSize + 10 + FooterSize},
+		{3, 1, HeaderSize + 100 + FooterSize},
+	}
+	if len(manifest) != len(expect) {
+		t.Fatalf("expect %d shards, actual %v", len(expect), manifest)
+	}
+	for i, info := range manifest {
+		if info != expect[i] {
+			t.Errorf("shard %d, expect %+v, actual %+v", i, expect[i], info)
+		}
+		if !shards[i].closed || int64(shards[i].Len()) != info.Bytes {
+			t.Errorf("shard %d not closed or size mismatch, %d bytes", i, shards[i].Len())
+		}
+		n, err := CountInRange(bytes.NewReader(shards[i].Bytes()), 0, maxInt64)
+		if err != nil || n != info.Records {
+			t.Errorf("shard %d, expect %d records, actual %d, %v", i, info.Records, n, err)
+		}
+	}
+}
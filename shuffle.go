@@ -0,0 +1,38 @@
+package tfrecord
+
+import (
+	"io"
+	"math/rand"
+)
+
+// ShuffleFile writes all records from current position of src to dst in random order permuted by rng, it
+// returns the number of records written. Only the index of src is held in memory, payloads are read one by
+// one while writing, so the same rng seed always produces the same order.
+func ShuffleFile(src io.ReadSeeker, dst *Writer, rng *rand.Rand) (int, error) {
+	ra, err := newReaderAt(src)
+	if err != nil {
+		return 0, err
+	}
+	index, err := BuildIndex(src)
+	if err != nil {
+		return 0, err
+	}
+	rng.Shuffle(len(index), func(i, j int) {
+		index[i], index[j] = index[j], index[i]
+	})
+	return writeIndexed(NewIndexedReader(ra, true), index, dst)
+}
+
+// writeIndexed writes records at index to dst in order.
+func writeIndexed(r *IndexedReader, index []RecordLocation, dst *Writer) (int, error) {
+	for i, loc := range index {
+		record, err := r.ReadAt(loc)
+		if err != nil {
+			return i, err
+		}
+		if _, err := dst.Write(record); err != nil {
+			return i, err
+		}
+	}
+	return len(index), nil
+}
@@ -0,0 +1,59 @@
+package tfrecord
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func readAllStrings(t *testing.T, data []byte) []string {
+	var out []string
+	it := NewIterator(bytes.NewReader(data), 16, true)
+	for it.Next() {
+		out = append(out, string(it.Value()))
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("read error %v", err)
+	}
+	return out
+}
+
+func TestShuffleFile(t *testing.T) {
+	data := writeTestRecords(t, 100)
+	shuffle := func(src io.ReadSeeker) []string {
+		buf := &bytes.Buffer{}
+		n, err := ShuffleFile(src, NewWriter(buf), rand.New(rand.NewSource(42)))
+		if n != 100 || err != nil {
+			t.Fatalf("expect 100 records shuffled, actual %d, err %v", n, err)
+		}
+		return readAllStrings(t, buf.Bytes())
+	}
+
+	first := shuffle(bytes.NewReader(data))
+	second := shuffle(struct{ io.ReadSeeker }{bytes.NewReader(data)})
+	inOrder := true
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("unstable order with same seed at %d, %s vs %s", i, first[i], second[i])
+		}
+		if first[i] != strconv.Itoa(i) {
+			inOrder = false
+		}
+	}
+	if inOrder {
+		t.Errorf("records not shuffled")
+	}
+	sort.Slice(first, func(i, j int) bool {
+		a, _ := strconv.Atoi(first[i])
+		b, _ := strconv.Atoi(first[j])
+		return a < b
+	})
+	for i, v := range first {
+		if v != strconv.Itoa(i) {
+			t.Fatalf("shuffled records are not a permutation, %v", first)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package tfrecord
+
+import (
+	"io"
+	"sort"
+)
+
+// SortFile writes all records from current position of src to dst sorted by key, it returns the number of
+// records written. key extracts sort key from a record, the record passed to it is only valid during the call,
+// cmp compares keys and returns negative, zero or positive like bytes.Compare, the sort is stable.
+//
+// All keys and the record index are held in memory while payloads are read one by one when writing, so memory
+// use is bound by number of records times key size, SortFile is not suitable for datasets whose keys don't fit
+// in memory.
+func SortFile(src io.ReadSeeker, dst *Writer, key func([]byte) ([]byte, error), cmp func(a, b []byte) int) (int, error) {
+	ra, err := newReaderAt(src)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		index []RecordLocation
+		keys  [][]byte
+	)
+	it := NewIterator(src, defaultBufSize, true)
+	for it.Next() {
+		k, err := key(it.Value())
+		if err != nil {
+			return 0, err
+		}
+		index = append(index, RecordLocation{Offset: it.recordOffset, Length: uint64(len(it.Value()))})
+		keys = append(keys, append([]byte(nil), k...))
+	}
+	if err := it.Err(); err != nil {
+		return 0, err
+	}
+	sort.Stable(&keySorter{index: index, keys: keys, cmp: cmp})
+	return writeIndexed(NewIndexedReader(ra, true), index, dst)
+}
+
+type keySorter struct {
+	index []RecordLocation
+	keys  [][]byte
+	cmp   func(a, b []byte) int
+}
+
+func (s *keySorter) Len() int {
+	return len(s.index)
+}
+
+func (s *keySorter) Less(i, j int) bool {
+	return s.cmp(s.keys[i], s.keys[j]) < 0
+}
+
+func (s *keySorter) Swap(i, j int) {
+	s.index[i], s.index[j] = s.index[j], s.index[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
@@ -0,0 +1,37 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSortFile(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	for _, r := range []string{"c:1", "a:2", "b:3", "a:4", "c:5"} {
+		if _, err := w.Write([]byte(r)); err != nil {
+			t.Fatalf("failed writing %v", err)
+		}
+	}
+	key := func(record []byte) ([]byte, error) {
+		return record[:1], nil
+	}
+	out := &bytes.Buffer{}
+	n, err := SortFile(bytes.NewReader(buf.Bytes()), NewWriter(out), key, bytes.Compare)
+	if n != 5 || err != nil {
+		t.Fatalf("expect 5 records sorted, actual %d, err %v", n, err)
+	}
+	if actual := strings.Join(readAllStrings(t, out.Bytes()), ","); actual != "a:2,a:4,b:3,c:1,c:5" {
+		t.Errorf("unexpected sorted records %s", actual)
+	}
+
+	errKey := errors.New("bad key")
+	_, err = SortFile(bytes.NewReader(buf.Bytes()), NewWriter(&bytes.Buffer{}), func([]byte) ([]byte, error) {
+		return nil, errKey
+	}, bytes.Compare)
+	if err != errKey {
+		t.Errorf("expect key error, actual %v", err)
+	}
+}
@@ -0,0 +1,226 @@
+package tfrecord
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+// scanHeaders reads record headers from r and calls fn with each complete record's length, payloads are skipped
+// without being read or checked. When r is an io.Seeker payloads are skipped by seeking, otherwise they are
+// read and discarded. Offsets in returned errors are relative to the starting position of r.
+func scanHeaders(r io.Reader, fn func(length uint64) error) error {
+	var (
+		header [HeaderSize]byte
+		offset int64
+		skip   func(n int64) error
+	)
+	if s, ok := r.(io.Seeker); ok {
+		start, err := s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		end, err := s.Seek(0, io.SeekEnd)
+		if err != nil {
+			return err
+		}
+		if _, err := s.Seek(start, io.SeekStart); err != nil {
+			return err
+		}
+		skip = func(n int64) error {
+			if end-start-offset < n {
+				return io.ErrUnexpectedEOF
+			}
+			_, err := s.Seek(n, io.SeekCurrent)
+			return err
+		}
+	} else {
+		skip = func(n int64) error {
+			_, err := io.CopyN(io.Discard, r, n)
+			if err == io.EOF {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+	}
+
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			if err == io.ErrUnexpectedEOF {
+				return &RecordError{Offset: offset, Err: ErrTruncated}
+			}
+			return err
+		}
+		length, err := parseHeader(header[:])
+		if err != nil {
+			return &RecordError{
+				Offset:      offset,
+				StoredCRC:   binary.LittleEndian.Uint32(header[LengthSize:]),
+				ComputedCRC: checksum(header[:LengthSize]),
+				Err:         err,
+			}
+		}
+		if length > uint64(maxInt64-FooterSize) {
+			return &RecordError{Offset: offset, Err: ErrTruncated}
+		}
+		frameRest := int64(length) + FooterSize
+		offset += HeaderSize
+		if err := skip(frameRest); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return &RecordError{Offset: offset - HeaderSize, Err: ErrTruncated}
+			}
+			return err
+		}
+		// only complete records are reported, a truncated one fails the scan instead.
+		if err := fn(length); err != nil {
+			return err
+		}
+		offset += frameRest
+	}
+}
+
+// Count counts records in r, reading and checking only record headers like CountInRange. It works over a
+// gzip.Reader as well, but a deflate stream can't be skipped without decompressing it, so payloads are still
+// decompressed, just discarded instead of being buffered and checksummed. Seekable index formats like bgzip
+// aren't supported, decompression of the whole file is the cost of counting a compressed file.
+func Count(r io.Reader) (int, error) {
+	return CountInRange(r, 0, maxInt64)
+}
+
+// CountInRange counts records in r whose length is within [min, max]. Only record headers are read and checked,
+// when r is an io.Seeker payloads are skipped by seeking.
+func CountInRange(r io.Reader, min, max uint64) (int, error) {
+	n := 0
+	err := scanHeaders(r, func(length uint64) error {
+		if length >= min && length <= max {
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
+
+// Report is aggregate statistics of records in a file, computed by Profile.
+type Report struct {
+	Records int
+	// TotalBytes is total payload size, framing excluded.
+	TotalBytes int64
+	// MinSize, MaxSize and MeanSize are of payload size, all zero when there's no record.
+	MinSize  int
+	MaxSize  int
+	MeanSize float64
+	// CRCFailures counts records failing data CRC check, they're included in other statistics.
+	CRCFailures int
+}
+
+// Profile computes Report of records in r in a single pass, data CRCs are checked and failures counted
+// without stopping. It stops on errors making following content unreadable, like length CRC failure or
+// truncation, returning the report of records read so far with the error.
+func Profile(r io.Reader) (Report, error) {
+	var report Report
+	it := NewIterator(r, defaultBufSize, true, WithCorruptRecords(true))
+	for it.Next() {
+		size := len(it.Value())
+		if report.Records == 0 || size < report.MinSize {
+			report.MinSize = size
+		}
+		report.MaxSize = max(report.MaxSize, size)
+		report.Records++
+		report.TotalBytes += int64(size)
+		if !it.LastCRCValid() {
+			report.CRCFailures++
+		}
+	}
+	if report.Records > 0 {
+		report.MeanSize = float64(report.TotalBytes) / float64(report.Records)
+	}
+	return report, it.Err()
+}
+
+// WriteSizeReport computes histogram of record lengths in r and writes it to w as a table. buckets are upper
+// bounds, inclusive, of each bucket, records longer than the largest bound go to an overflow bucket. Each row
+// reports the bucket's record count and cumulative percentage of records.
+func WriteSizeReport(r io.Reader, w io.Writer, buckets []uint64) error {
+	bounds := append([]uint64(nil), buckets...)
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+	counts := make([]int, len(bounds)+1)
+	total := 0
+	err := scanHeaders(r, func(length uint64) error {
+		counts[sort.Search(len(bounds), func(i int) bool { return length <= bounds[i] })]++
+		total++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "size\tcount\tcumulative\n")
+	cumulative := 0
+	for i, count := range counts {
+		var label string
+		switch {
+		case i < len(bounds):
+			label = "<= " + strconv.FormatUint(bounds[i], 10)
+		case len(bounds) > 0:
+			label = "> " + strconv.FormatUint(bounds[len(bounds)-1], 10)
+		default:
+			label = "all"
+		}
+		cumulative += count
+		percent := 0.0
+		if total > 0 {
+			percent = float64(cumulative) * 100 / float64(total)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%.1f%%\n", label, count, percent)
+	}
+	fmt.Fprintf(tw, "total\t%d\n", total)
+	return tw.Flush()
+}
+
+// errSampled stops scanHeaders once enough records are sampled.
+var errSampled = errors.New("sampled enough records")
+
+// EstimateBufferSize suggests an iterator bufSize for r: the 95th percentile of payload sizes of its first
+// sampleRecords records, all records if sampleRecords <= 0, so most records fit without allocation. Only
+// headers are read, payloads are skipped by seeking, and r is seeked back to where it was before returning.
+// It returns 0 for a stream with no record, which makes the iterator buffer adaptive.
+func EstimateBufferSize(r io.ReadSeeker, sampleRecords int) (size int64, err error) {
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if _, serr := r.Seek(start, io.SeekStart); serr != nil && err == nil {
+			size, err = 0, serr
+		}
+	}()
+	var sizes []uint64
+	err = scanHeaders(r, func(length uint64) error {
+		sizes = append(sizes, length)
+		if len(sizes) == sampleRecords {
+			return errSampled
+		}
+		return nil
+	})
+	if err != nil && err != errSampled {
+		return 0, err
+	}
+	if len(sizes) == 0 {
+		return 0, nil
+	}
+	slices.Sort(sizes)
+	p95 := sizes[(len(sizes)*95+99)/100-1]
+	if p95 > maxInt64 {
+		return 0, fmt.Errorf("record of %d bytes: %w", p95, ErrRecordTooLarge)
+	}
+	return int64(p95), nil
+}
@@ -0,0 +1,136 @@
+package tfrecord
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func writeSizedRecords(t testing.TB, sizes ...int) []byte {
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	for _, size := range sizes {
+		if _, err := w.Write(bytes.Repeat([]byte("x"), size)); err != nil {
+			t.Fatalf("failed writing %v", err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestCountInRange(t *testing.T) {
+	data := writeSizedRecords(t, 0, 5, 10, 100, 1000, 10)
+	readers := map[string]func([]byte) io.Reader{
+		"seeker": func(b []byte) io.Reader { return bytes.NewReader(b) },
+		"stream": func(b []byte) io.Reader { return struct{ io.Reader }{bytes.NewReader(b)} },
+	}
+	for name, reader := range readers {
+		for _, c := range []struct {
+			min, max uint64
+			expect   int
+		}{
+			{0, 0, 1},
+			{5, 10, 3},
+			{11, 1 << 40, 2},
+			{2000, 3000, 0},
+		} {
+			n, err := CountInRange(reader(data), c.min, c.max)
+			if err != nil || n != c.expect {
+				t.Errorf("%s [%d, %d]: expect %d, actual %d, err %v", name, c.min, c.max, c.expect, n, err)
+			}
+		}
+
+		truncated := data[:len(data)-1]
+		if n, err := CountInRange(reader(truncated), 0, 100); n != 4 || !errors.Is(err, ErrTruncated) {
+			t.Errorf("%s: expect 4 complete records then ErrTruncated, actual %d, %v", name, n, err)
+		}
+	}
+
+	if _, err := CountInRange(strings.NewReader("garbage header"), 0, 100); !errors.Is(err, ErrChecksum) {
+		t.Errorf("expect ErrChecksum, actual %v", err)
+	}
+}
+
+func TestCountGzip(t *testing.T) {
+	f, err := os.Open("testdata/test.tfrecord.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := Count(zr); err != nil || n != 4 {
+		t.Errorf("expect 4 records, actual %d, %v", n, err)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	data := writeSizedRecords(t, 3, 0, 10, 7)
+	data[HeaderSize+3+FooterSize+FrameSize(0)+HeaderSize]++
+	report, err := Profile(bytes.NewReader(data))
+	expect := Report{Records: 4, TotalBytes: 20, MinSize: 0, MaxSize: 10, MeanSize: 5, CRCFailures: 1}
+	if err != nil || report != expect {
+		t.Errorf("expect %+v, actual %+v, %v", expect, report, err)
+	}
+	if report, err := Profile(bytes.NewReader(nil)); err != nil || report != (Report{}) {
+		t.Errorf("expect empty report, actual %+v, %v", report, err)
+	}
+}
+
+func TestWriteSizeReport(t *testing.T) {
+	data := writeSizedRecords(t, 0, 5, 10, 100, 1000, 10)
+	out := &bytes.Buffer{}
+	if err := WriteSizeReport(bytes.NewReader(data), out, []uint64{100, 10}); err != nil {
+		t.Fatalf("failed writing report %v", err)
+	}
+	expect := `
+size    count  cumulative
+<= 10   4      66.7%
+<= 100  1      83.3%
+> 100   1      100.0%
+total   6
+`
+	if got := out.String(); got != expect[1:] {
+		t.Errorf("unmatched report, expect\n%s\nactual\n%s", expect[1:], got)
+	}
+
+	out.Reset()
+	if err := WriteSizeReport(bytes.NewReader(data), out, nil); err != nil {
+		t.Fatalf("failed writing report without buckets %v", err)
+	}
+	expect = `
+size   count  cumulative
+all    6      100.0%
+total  6
+`
+	if got := out.String(); got != expect[1:] {
+		t.Errorf("unmatched report without buckets, expect\n%s\nactual\n%s", expect[1:], got)
+	}
+}
+
+func TestEstimateBufferSize(t *testing.T) {
+	sizes := make([]int, 100)
+	for i := range sizes {
+		sizes[i] = i + 1
+	}
+	sizes[50] = 100000
+	r := bytes.NewReader(writeSizedRecords(t, sizes...))
+	r.Seek(FrameSize(1), io.SeekStart)
+	if n, err := EstimateBufferSize(r, 0); err != nil || n != 97 {
+		t.Errorf("expect 95th percentile 97, actual %d, %v", n, err)
+	}
+	if pos, _ := r.Seek(0, io.SeekCurrent); pos != FrameSize(1) {
+		t.Errorf("expect position restored to %d, actual %d", FrameSize(1), pos)
+	}
+	if n, err := EstimateBufferSize(r, 20); err != nil || n != 20 {
+		t.Errorf("expect 95th percentile of 20 records 20, actual %d, %v", n, err)
+	}
+	if n, err := EstimateBufferSize(bytes.NewReader(nil), 20); err != nil || n != 0 {
+		t.Errorf("expect 0 for empty stream, actual %d, %v", n, err)
+	}
+}
@@ -0,0 +1,137 @@
+package tfrecord
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+)
+
+// NextStream moves to next record and returns a reader of its payload, for records too large to hold in
+// memory. The data CRC is computed while reading and checked when the payload is fully consumed, a mismatch is
+// returned by the reader's last Read and by Err. It returns io.EOF at clean end of stream. The payload reader
+// must be drained before next call to Next, NextReuse or NextStream, otherwise they drain it first, discarding
+// the remaining payload. With WithReadahead the record is already buffered and checked, the reader is on top of
+// it.
+func (it *Iterator) NextStream() (payload io.Reader, length uint64, err error) {
+	if it.closed {
+		return nil, 0, it.eofOrErr()
+	}
+	if it.readahead > 0 {
+		if !it.Next() {
+			return nil, 0, it.eofOrErr()
+		}
+		return bytes.NewReader(it.value), uint64(len(it.value)), nil
+	}
+	if !it.drainStream() {
+		it.finish()
+		return nil, 0, it.err
+	}
+	it.value, it.valueOwned = nil, false
+	offset, length, err := it.readHeader()
+	if err != nil {
+		if err != io.EOF {
+			it.err = err
+		} else {
+			it.err = it.checkCount()
+		}
+		it.finish()
+		return nil, 0, it.eofOrErr()
+	}
+	it.part = partialFrame{}
+	if it.trace != nil {
+		if it.err = it.traceRecord(offset, int(length)); it.err != nil {
+			it.finish()
+			return nil, 0, it.err
+		}
+	}
+	it.recordOffset, it.crcValid = offset, true
+	it.offset = offset + int64(it.headerLen()) + int64(length) + int64(it.footerLen())
+	it.ordinal++
+	for _, m := range it.metrics {
+		m.RecordRead(it.headerLen() + int(length) + it.footerLen())
+	}
+	stream := &payloadStream{it: it, length: length, rest: length}
+	it.stream = stream
+	if length == 0 {
+		stream.err = stream.finish()
+	}
+	return stream, length, nil
+}
+
+func (it *Iterator) eofOrErr() error {
+	if it.err != nil {
+		return it.err
+	}
+	return io.EOF
+}
+
+// drainStream discards what's left of the payload returned by NextStream, it returns false on error.
+func (it *Iterator) drainStream() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.stream != nil {
+		io.Copy(io.Discard, it.stream)
+	}
+	return it.err == nil
+}
+
+// payloadStream reads payload of a record returned by NextStream.
+type payloadStream struct {
+	it     *Iterator
+	length uint64
+	rest   uint64
+	crc    uint32
+	err    error
+}
+
+func (s *payloadStream) Read(p []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	if uint64(len(p)) > s.rest {
+		p = p[:s.rest]
+	}
+	n, err := s.it.in.Read(p)
+	s.crc = crc32.Update(s.crc, crc32Table, p[:n])
+	s.rest -= uint64(n)
+	switch {
+	case s.rest == 0:
+		s.err = s.finish()
+	case err == io.EOF:
+		s.fail(&RecordError{Offset: s.it.recordOffset, Err: ErrTruncated})
+	case err != nil:
+		s.fail(err)
+	}
+	return n, s.err
+}
+
+// finish reads footer and checks data CRC, it returns io.EOF if the record is intact.
+func (s *payloadStream) finish() error {
+	it := s.it
+	it.stream = nil
+	if _, err := io.ReadFull(it.in, it.footer[:it.footerLen()]); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = &RecordError{Offset: it.recordOffset, Err: ErrTruncated}
+		}
+		return s.fail(err)
+	}
+	it.readOffset += int64(s.length) + int64(it.footerLen())
+	it.dataCRC = binary.LittleEndian.Uint32(it.footer[:])
+	if crc := maskCRC(s.crc); it.checkDataCRC && it.footerLen() > 0 && crc != it.dataCRC {
+		it.checksumFailure()
+		if !it.keepCorrupt {
+			return s.fail(&RecordError{Offset: it.recordOffset, StoredCRC: it.dataCRC, ComputedCRC: crc, Err: ErrChecksum})
+		}
+		it.crcValid = false
+	}
+	return io.EOF
+}
+
+func (s *payloadStream) fail(err error) error {
+	s.err = err
+	s.it.err = err
+	s.it.stream = nil
+	return err
+}
@@ -0,0 +1,59 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestNextStream(t *testing.T) {
+	large := bytes.Repeat([]byte("0123456789"), 10000)
+	data := writeSizedRecords(t, 0, 3)
+	buf := bytes.NewBuffer(data)
+	NewWriter(buf).Write(large)
+	data = buf.Bytes()
+
+	it := NewIterator(bytes.NewReader(data), 16, true)
+	// first record is never read, next call drains it.
+	if _, n, err := it.NextStream(); err != nil || n != 0 {
+		t.Fatalf("expect empty record, actual %d, %v", n, err)
+	}
+	if !it.Next() || string(it.Value()) != "xxx" {
+		t.Fatalf("expect record xxx, actual %q, %v", it.Value(), it.Err())
+	}
+	r, n, err := it.NextStream()
+	if err != nil || n != uint64(len(large)) {
+		t.Fatalf("expect record of %d bytes, actual %d, %v", len(large), n, err)
+	}
+	if payload, err := io.ReadAll(r); err != nil || !bytes.Equal(payload, large) {
+		t.Errorf("unexpected payload of %d bytes, %v", len(payload), err)
+	}
+	if _, _, err := it.NextStream(); err != io.EOF {
+		t.Errorf("expect io.EOF, actual %v", err)
+	}
+	if it.Err() != nil {
+		t.Errorf("unexpected error %v", it.Err())
+	}
+
+	data[len(data)-FooterSize-1]++
+	it = NewIterator(bytes.NewReader(data), 16, true)
+	it.Next()
+	it.Next()
+	r, _, _ = it.NextStream()
+	if _, err := io.ReadAll(r); !errors.Is(err, ErrChecksum) {
+		t.Errorf("expect ErrChecksum, actual %v", err)
+	}
+	if it.Next() || !errors.Is(it.Err(), ErrChecksum) {
+		t.Errorf("expect iteration stopped with ErrChecksum, actual %v", it.Err())
+	}
+
+	it = NewIterator(bytes.NewReader(data[:len(data)-100]), 16, true)
+	it.Next()
+	it.Next()
+	r, _, _ = it.NextStream()
+	var re *RecordError
+	if _, err := io.ReadAll(r); !errors.As(err, &re) || re.Err != ErrTruncated || re.Offset != int64(len(data))-FrameSize(len(large)) {
+		t.Errorf("expect ErrTruncated at last record, actual %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package tfrecord
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+)
+
+// VerifyingTeeReader returns a reader that reads from r, checks length and data CRCs of records passing
+// through and writes all bytes it reads to w, so a stream can be validated while being forwarded, in a single
+// pass. Validation failures surface as read errors of the returned reader, as a *RecordError, and bytes of a
+// failing read are not forwarded to w. A stream ending in the middle of a record fails with ErrTruncated
+// instead of io.EOF. Memory use is constant regardless of record size.
+func VerifyingTeeReader(r io.Reader, w io.Writer) io.Reader {
+	return &verifyingTeeReader{r: r, w: w}
+}
+
+type verifyingTeeReader struct {
+	r   io.Reader
+	w   io.Writer
+	v   frameValidator
+	err error
+}
+
+func (t *verifyingTeeReader) Read(p []byte) (int, error) {
+	if t.err != nil {
+		return 0, t.err
+	}
+	n, err := t.r.Read(p)
+	if verr := t.v.write(p[:n]); verr != nil {
+		t.err = verr
+		return 0, verr
+	}
+	if n > 0 {
+		if werr := writeFull(t.w, p[:n]); werr != nil {
+			t.err = werr
+			return n, werr
+		}
+	}
+	if err == io.EOF {
+		if verr := t.v.close(); verr != nil {
+			err = verr
+		}
+	}
+	t.err = err
+	return n, err
+}
+
+// frameValidator checks CRCs of TFRecord frames fed to it in arbitrary chunks, holding no more than a header.
+type frameValidator struct {
+	// offset is stream position of the frame being validated.
+	offset int64
+	header [HeaderSize]byte
+	footer [FooterSize]byte
+	// pos is number of bytes of current frame consumed.
+	pos    uint64
+	length uint64
+	crc    uint32
+}
+
+// write validates p, continuing frame validation where previous write stopped.
+func (v *frameValidator) write(p []byte) error {
+	for len(p) > 0 {
+		switch {
+		case v.pos < HeaderSize:
+			n := copy(v.header[v.pos:], p)
+			v.pos += uint64(n)
+			p = p[n:]
+			if v.pos < HeaderSize {
+				continue
+			}
+			length, err := parseHeader(v.header[:])
+			if err != nil {
+				return &RecordError{
+					Offset:      v.offset,
+					StoredCRC:   binary.LittleEndian.Uint32(v.header[LengthSize:]),
+					ComputedCRC: checksum(v.header[:LengthSize]),
+					Err:         err,
+				}
+			}
+			v.length, v.crc = length, 0
+		case v.pos < HeaderSize+v.length:
+			n := uint64(len(p))
+			if rest := HeaderSize + v.length - v.pos; n > rest {
+				n = rest
+			}
+			v.crc = crc32.Update(v.crc, crc32Table, p[:n])
+			v.pos += n
+			p = p[n:]
+		default:
+			n := copy(v.footer[v.pos-HeaderSize-v.length:], p)
+			v.pos += uint64(n)
+			p = p[n:]
+			if v.pos < HeaderSize+v.length+FooterSize {
+				continue
+			}
+			stored := binary.LittleEndian.Uint32(v.footer[:])
+			if crc := maskCRC(v.crc); crc != stored {
+				return &RecordError{Offset: v.offset, StoredCRC: stored, ComputedCRC: crc, Err: ErrChecksum}
+			}
+			v.offset += int64(v.pos)
+			v.pos = 0
+		}
+	}
+	return nil
+}
+
+// close checks the stream doesn't end in the middle of a frame.
+func (v *frameValidator) close() error {
+	if v.pos != 0 {
+		return &RecordError{Offset: v.offset, Err: ErrTruncated}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestVerifyingTeeReader(t *testing.T) {
+	data := writeSizedRecords(t, 0, 10, 100000, 3)
+	out := &bytes.Buffer{}
+	if _, err := io.Copy(io.Discard, VerifyingTeeReader(iotest.OneByteReader(bytes.NewReader(data)), out)); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), data) {
+		t.Errorf("forwarded bytes differ from input")
+	}
+
+	frameSize := int64(HeaderSize + 10 + FooterSize)
+	for _, c := range []struct {
+		name   string
+		data   func() []byte
+		err    error
+		offset int64
+	}{
+		{"data crc", func() []byte { d := bytes.Clone(data); d[HeaderSize+HeaderSize+5]++; return d }, ErrChecksum, HeaderSize + FooterSize},
+		{"length crc", func() []byte { d := bytes.Clone(data); d[HeaderSize+FooterSize+1]++; return d }, ErrChecksum, HeaderSize + FooterSize},
+		{"truncated", func() []byte { return data[:len(data)-2] }, ErrTruncated, HeaderSize + FooterSize + frameSize + HeaderSize + 100000 + FooterSize},
+	} {
+		_, err := io.Copy(io.Discard, VerifyingTeeReader(bytes.NewReader(c.data()), io.Discard))
+		var re *RecordError
+		if !errors.Is(err, c.err) || !errors.As(err, &re) || re.Offset != c.offset {
+			t.Errorf("%s: expect %v at offset %d, actual %v", c.name, c.err, c.offset, err)
+		}
+	}
+}
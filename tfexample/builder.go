@@ -0,0 +1,47 @@
+package tfexample
+
+// ExampleBuilder builds a serialized tf.train.Example, ready for tfrecord.Writer.Write. Its zero value is an
+// empty Example, features are encoded in the order they're first added.
+type ExampleBuilder struct {
+	keys     []string
+	features []Feature
+}
+
+// AddBytes sets feature key to a bytes_list of vals, replacing any feature of the same key. It returns b for
+// chaining.
+func (b *ExampleBuilder) AddBytes(key string, vals ...[]byte) *ExampleBuilder {
+	return b.add(key, Feature{Kind: KindBytes, Bytes: vals})
+}
+
+// AddFloat sets feature key to a float_list of vals, like AddBytes.
+func (b *ExampleBuilder) AddFloat(key string, vals ...float32) *ExampleBuilder {
+	return b.add(key, Feature{Kind: KindFloat, Float: vals})
+}
+
+// AddInt64 sets feature key to an int64_list of vals, like AddBytes.
+func (b *ExampleBuilder) AddInt64(key string, vals ...int64) *ExampleBuilder {
+	return b.add(key, Feature{Kind: KindInt64, Int64: vals})
+}
+
+func (b *ExampleBuilder) add(key string, f Feature) *ExampleBuilder {
+	for i, k := range b.keys {
+		if k == key {
+			b.features[i] = f
+			return b
+		}
+	}
+	b.keys, b.features = append(b.keys, key), append(b.features, f)
+	return b
+}
+
+// Build returns the Example in protobuf wire format. Values are copied, b can be modified or reused after.
+func (b *ExampleBuilder) Build() []byte {
+	var features []byte
+	for i, key := range b.keys {
+		features = appendMapEntry(features, featuresFeature, key, appendFeature(nil, b.features[i]))
+	}
+	if len(features) == 0 {
+		return []byte{}
+	}
+	return appendMessage(nil, exampleFeatures, features)
+}
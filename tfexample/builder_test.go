@@ -0,0 +1,83 @@
+package tfexample
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"testing"
+
+	"github.com/kuangyh/tfrecord"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestExampleBuilder(t *testing.T) {
+	var b ExampleBuilder
+	record := b.AddBytes("image", []byte("png"), nil).
+		AddFloat("score", 0.5, float32(math.Inf(-1))).
+		AddInt64("label", 0).
+		AddInt64("label", 3, -4).
+		AddFloat("empty").
+		Build()
+
+	// Decode with the protobuf runtime and TensorFlow's schema, to check wire compatibility.
+	m := parseWithSchema(t, "Example", record)
+	features := m.Get(m.Descriptor().Fields().ByName("features")).Message()
+	featureMap := features.Get(features.Descriptor().Fields().ByName("feature")).Map()
+	if featureMap.Len() != 4 {
+		t.Fatalf("expect 4 features, actual %d", featureMap.Len())
+	}
+	list := func(key, kind string) protoreflect.List {
+		f := featureMap.Get(protoreflect.ValueOfString(key).MapKey()).Message()
+		fd := f.Descriptor().Fields().ByName(protoreflect.Name(kind))
+		if !f.Has(fd) {
+			t.Fatalf("expect feature %s with %s", key, kind)
+		}
+		l := f.Get(fd).Message()
+		return l.Get(l.Descriptor().Fields().ByName("value")).List()
+	}
+	if l := list("image", "bytes_list"); l.Len() != 2 || string(l.Get(0).Bytes()) != "png" || len(l.Get(1).Bytes()) != 0 {
+		t.Errorf("unexpected image feature")
+	}
+	if l := list("score", "float_list"); l.Len() != 2 || l.Get(0).Float() != 0.5 || !math.IsInf(l.Get(1).Float(), -1) {
+		t.Errorf("unexpected score feature")
+	}
+	if l := list("label", "int64_list"); l.Len() != 2 || l.Get(0).Int() != 3 || l.Get(1).Int() != -4 {
+		t.Errorf("unexpected label feature")
+	}
+	if l := list("empty", "float_list"); l.Len() != 0 {
+		t.Errorf("expect empty feature, actual %d values", l.Len())
+	}
+
+	if v, found, err := ExampleFeatureInt64(record, "label"); err != nil || !found || len(v) != 2 || v[1] != -4 {
+		t.Errorf("expect label read back, actual %v, %v, %v", v, found, err)
+	}
+	if record := new(ExampleBuilder).Build(); len(record) != 0 {
+		t.Errorf("expect empty Example, actual %q", record)
+	}
+}
+
+// tfExample is the TFRecord file of the Example in testdata/gen_fixtures.py, serialized deterministically with
+// TensorFlow's example.proto.
+const tfExample = "54000000000000005f5145870a520a0b0a05656d707479120212000a120a05696d61676512090a070a03706e670a000a" +
+	"180a056c6162656c120f1a0d0a0b03fcffffffffffffffff010a150a0573636f7265120c120a0a080000003f000080ff" +
+	"deeb0d9d"
+
+func TestExampleBuilderMatchesTF(t *testing.T) {
+	data, err := hex.DecodeString(tfExample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	it := tfrecord.NewIterator(bytes.NewReader(data), 0, true)
+	if !it.Next() {
+		t.Fatalf("expect an Example, err %v", it.Err())
+	}
+	var b ExampleBuilder
+	record := b.AddFloat("empty").
+		AddBytes("image", []byte("png"), nil).
+		AddInt64("label", 3, -4).
+		AddFloat("score", 0.5, float32(math.Inf(-1))).
+		Build()
+	if !bytes.Equal(record, it.Value()) {
+		t.Errorf("expect Example serialized by TensorFlow %x, actual %x", it.Value(), record)
+	}
+}
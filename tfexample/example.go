@@ -0,0 +1,127 @@
+// Package tfexample reads tf.train.Example records straight from protobuf wire format, without generated
+// TensorFlow protos. It's a separate module so the core tfrecord module doesn't depend on protobuf.
+package tfexample
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Kind is kind of values a Feature holds.
+type Kind int
+
+const (
+	// KindNone is a Feature with no value list set.
+	KindNone Kind = iota
+	// KindBytes is a Feature with bytes_list.
+	KindBytes
+	// KindFloat is a Feature with float_list.
+	KindFloat
+	// KindInt64 is a Feature with int64_list.
+	KindInt64
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNone:
+		return "none"
+	case KindBytes:
+		return "bytes"
+	case KindFloat:
+		return "float"
+	case KindInt64:
+		return "int64"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Feature is a decoded tf.train.Feature, only the list of its Kind is set.
+type Feature struct {
+	Kind  Kind
+	Bytes [][]byte
+	Float []float32
+	Int64 []int64
+}
+
+// ParseExample decodes a serialized tf.train.Example and returns its features by name, bytes values alias
+// record. Use ExampleFeatureBytes and its variants to get a single feature without decoding the others.
+func ParseExample(record []byte) (map[string]Feature, error) {
+	return parseFeatures(record, exampleFeatures)
+}
+
+// parseFeatures decodes tf.train.Features message field num of b.
+func parseFeatures(b []byte, num protowire.Number) (map[string]Feature, error) {
+	features := map[string]Feature{}
+	err := scanMessages(b, num, func(fs []byte) error {
+		return scanMap(fs, featuresFeature, func(key, value []byte) error {
+			f, err := parseFeature(value)
+			if err != nil {
+				return fmt.Errorf("feature %q: %w", key, err)
+			}
+			features[string(key)] = f
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return features, nil
+}
+
+// findFeature scans Example in record for feature key, only that feature is decoded. As in protobuf maps, the
+// last entry of a repeated key wins.
+func findFeature(record []byte, key string) (Feature, bool, error) {
+	var (
+		raw   []byte
+		found bool
+	)
+	err := scanMessages(record, exampleFeatures, func(features []byte) error {
+		return scanMap(features, featuresFeature, func(k, v []byte) error {
+			if string(k) == key {
+				raw, found = v, true
+			}
+			return nil
+		})
+	})
+	if err != nil || !found {
+		return Feature{}, false, err
+	}
+	f, err := parseFeature(raw)
+	if err != nil {
+		return Feature{}, false, fmt.Errorf("feature %q: %w", key, err)
+	}
+	return f, true, nil
+}
+
+// lookup finds feature key of kind in record, a feature with no list set is an empty list of any kind.
+func lookup(record []byte, key string, kind Kind) (Feature, bool, error) {
+	f, found, err := findFeature(record, key)
+	if err != nil || !found {
+		return f, found, err
+	}
+	if f.Kind != kind && f.Kind != KindNone {
+		return Feature{}, false, fmt.Errorf("feature %q is %v list, not %v", key, f.Kind, kind)
+	}
+	return f, true, nil
+}
+
+// ExampleFeatureBytes returns bytes_list of feature key in the serialized tf.train.Example record, scanning
+// the wire format and decoding only that feature. Values alias record. found is false if there's no such
+// feature, an error is returned for malformed record or if the feature has another kind.
+func ExampleFeatureBytes(record []byte, key string) (values [][]byte, found bool, err error) {
+	f, found, err := lookup(record, key, KindBytes)
+	return f.Bytes, found, err
+}
+
+// ExampleFeatureFloat is ExampleFeatureBytes for float_list.
+func ExampleFeatureFloat(record []byte, key string) (values []float32, found bool, err error) {
+	f, found, err := lookup(record, key, KindFloat)
+	return f.Float, found, err
+}
+
+// ExampleFeatureInt64 is ExampleFeatureBytes for int64_list.
+func ExampleFeatureInt64(record []byte, key string) (values []int64, found bool, err error) {
+	f, found, err := lookup(record, key, KindInt64)
+	return f.Int64, found, err
+}
@@ -0,0 +1,88 @@
+package tfexample
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// encodeFeature encodes a tf.train.Feature of list kind holding content.
+func encodeFeature(kind protowire.Number, list []byte) []byte {
+	return protowire.AppendBytes(protowire.AppendTag(nil, kind, protowire.BytesType), list)
+}
+
+// encodeExample encodes a tf.train.Example with features in order, keys may repeat.
+func encodeExample(features ...any) []byte {
+	var fs []byte
+	for i := 0; i < len(features); i += 2 {
+		var entry []byte
+		entry = protowire.AppendTag(entry, mapKey, protowire.BytesType)
+		entry = protowire.AppendString(entry, features[i].(string))
+		entry = protowire.AppendTag(entry, mapValue, protowire.BytesType)
+		entry = protowire.AppendBytes(entry, features[i+1].([]byte))
+		fs = protowire.AppendTag(fs, featuresFeature, protowire.BytesType)
+		fs = protowire.AppendBytes(fs, entry)
+	}
+	return protowire.AppendBytes(protowire.AppendTag(nil, exampleFeatures, protowire.BytesType), fs)
+}
+
+func TestExampleFeature(t *testing.T) {
+	var bytesList, packedFloats, floats, packedInts, ints []byte
+	for _, v := range []string{"a", "", "bc"} {
+		bytesList = protowire.AppendTag(bytesList, listValue, protowire.BytesType)
+		bytesList = protowire.AppendString(bytesList, v)
+	}
+	var packed []byte
+	for _, v := range []float32{1.5, -2} {
+		packed = protowire.AppendFixed32(packed, math.Float32bits(v))
+		floats = protowire.AppendTag(floats, listValue, protowire.Fixed32Type)
+		floats = protowire.AppendFixed32(floats, math.Float32bits(v))
+	}
+	packedFloats = protowire.AppendBytes(protowire.AppendTag(nil, listValue, protowire.BytesType), packed)
+	packed = nil
+	for _, v := range []int64{7, -1} {
+		packed = protowire.AppendVarint(packed, uint64(v))
+		ints = protowire.AppendTag(ints, listValue, protowire.VarintType)
+		ints = protowire.AppendVarint(ints, uint64(v))
+	}
+	packedInts = protowire.AppendBytes(protowire.AppendTag(nil, listValue, protowire.BytesType), packed)
+
+	record := encodeExample(
+		"image", encodeFeature(featureBytesList, bytesList),
+		"score", encodeFeature(featureFloatList, packedFloats),
+		"score2", encodeFeature(featureFloatList, floats),
+		"label", encodeFeature(featureInt64List, ints),
+		"label", encodeFeature(featureInt64List, packedInts[:0]),
+		"label", encodeFeature(featureInt64List, packedInts),
+		"empty", []byte{},
+	)
+	if v, found, err := ExampleFeatureBytes(record, "image"); err != nil || !found || len(v) != 3 || string(v[2]) != "bc" {
+		t.Errorf("unexpected bytes feature %q, %v, %v", v, found, err)
+	}
+	for _, key := range []string{"score", "score2"} {
+		if v, found, err := ExampleFeatureFloat(record, key); err != nil || !found || !slices.Equal(v, []float32{1.5, -2}) {
+			t.Errorf("unexpected float feature %s %v, %v, %v", key, v, found, err)
+		}
+	}
+	if v, found, err := ExampleFeatureInt64(record, "label"); err != nil || !found || !slices.Equal(v, []int64{7, -1}) {
+		t.Errorf("unexpected int64 feature %v, %v, %v", v, found, err)
+	}
+	if v, found, err := ExampleFeatureInt64(record, "empty"); err != nil || !found || len(v) != 0 {
+		t.Errorf("expect empty feature found, actual %v, %v, %v", v, found, err)
+	}
+	if _, found, err := ExampleFeatureBytes(record, "missing"); err != nil || found {
+		t.Errorf("expect missing feature not found, actual %v, %v", found, err)
+	}
+	if _, _, err := ExampleFeatureBytes(record, "label"); err == nil {
+		t.Errorf("expect error reading int64 feature as bytes")
+	}
+	if _, _, err := ExampleFeatureBytes(nil, "label"); err != nil {
+		t.Errorf("expect empty Example valid, actual %v", err)
+	}
+	if _, _, err := ExampleFeatureBytes(record[:len(record)-1], "label"); !errors.Is(err, ErrMalformed) {
+		t.Errorf("expect ErrMalformed for truncated record, actual %v", err)
+	}
+}
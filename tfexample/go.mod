@@ -0,0 +1,8 @@
+module github.com/kuangyh/tfrecord/tfexample
+
+go 1.25.0
+
+require (
+	github.com/kuangyh/tfrecord v0.0.0-20261015094006-ff431aa3dd08
+	google.golang.org/protobuf v1.36.11
+)
@@ -0,0 +1,58 @@
+package tfexample
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"strconv"
+)
+
+// jsonFloat encodes NaN and infinities as strings like protobuf JSON mapping, as JSON numbers can't express
+// them.
+type jsonFloat float32
+
+func (f jsonFloat) MarshalJSON() ([]byte, error) {
+	switch v := float64(f); {
+	case math.IsNaN(v):
+		return []byte(`"NaN"`), nil
+	case math.IsInf(v, 1):
+		return []byte(`"Infinity"`), nil
+	case math.IsInf(v, -1):
+		return []byte(`"-Infinity"`), nil
+	}
+	return strconv.AppendFloat(nil, float64(f), 'g', -1, 32), nil
+}
+
+// ExampleToJSON decodes a serialized tf.train.Example into JSON for inspection, an object of feature names
+// mapped to their value list, like {"image": {"bytesList": ["iVBORw=="]}, "label": {"int64List": [3]}}.
+// Bytes are base64 encoded, float NaN and infinities are strings "NaN", "Infinity" and "-Infinity" as in
+// protobuf JSON mapping. An empty list is an empty array, a feature with no list set is {}. Keys are sorted, so
+// output is deterministic.
+func ExampleToJSON(record []byte) ([]byte, error) {
+	features, err := ParseExample(record)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]map[string]any, len(features))
+	for key, f := range features {
+		jf := map[string]any{}
+		switch f.Kind {
+		case KindBytes:
+			list := make([]string, len(f.Bytes))
+			for i, v := range f.Bytes {
+				list[i] = base64.StdEncoding.EncodeToString(v)
+			}
+			jf["bytesList"] = list
+		case KindFloat:
+			list := make([]jsonFloat, len(f.Float))
+			for i, v := range f.Float {
+				list[i] = jsonFloat(v)
+			}
+			jf["floatList"] = list
+		case KindInt64:
+			jf["int64List"] = f.Int64
+		}
+		out[key] = jf
+	}
+	return json.Marshal(out)
+}
@@ -0,0 +1,33 @@
+package tfexample
+
+import (
+	"math"
+	"testing"
+)
+
+func TestExampleToJSON(t *testing.T) {
+	var b ExampleBuilder
+	record := b.AddBytes("image", []byte("png"), nil).
+		AddFloat("score", 0.1, float32(math.NaN()), float32(math.Inf(1))).
+		AddInt64("label", 3, -4).
+		AddInt64("empty").
+		Build()
+	// A feature with no list set.
+	record = appendMessage(record, exampleFeatures, appendMapEntry(nil, featuresFeature, "none", nil))
+	out, err := ExampleToJSON(record)
+	if err != nil {
+		t.Fatalf("json error %v", err)
+	}
+	expect := `{"empty":{"int64List":[]},"image":{"bytesList":["cG5n",""]},"label":{"int64List":[3,-4]},` +
+		`"none":{},"score":{"floatList":[0.1,"NaN","Infinity"]}}`
+	if string(out) != expect {
+		t.Errorf("expect %s, actual %s", expect, out)
+	}
+
+	if out, err := ExampleToJSON(nil); err != nil || string(out) != "{}" {
+		t.Errorf("expect {} for empty Example, actual %s, %v", out, err)
+	}
+	if _, err := ExampleToJSON([]byte{0x0a, 0x05}); err == nil {
+		t.Errorf("expect error for malformed Example")
+	}
+}
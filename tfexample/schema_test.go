@@ -0,0 +1,111 @@
+package tfexample
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// tfSchema returns descriptors of TensorFlow's feature.proto and example.proto messages, so tests can encode
+// and decode with the protobuf runtime instead of this package.
+func tfSchema(t testing.TB) protoreflect.FileDescriptor {
+	t.Helper()
+	field := func(name string, num int32, label descriptorpb.FieldDescriptorProto_Label, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(num),
+			Label:    label.Enum(),
+			Type:     typ.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(".tensorflow." + typeName)
+		}
+		return f
+	}
+	const (
+		optional = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		repeated = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+		message  = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	)
+	list := func(name string, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.DescriptorProto {
+		value := field("value", 1, repeated, typ, "")
+		if typ != descriptorpb.FieldDescriptorProto_TYPE_BYTES {
+			value.Options = &descriptorpb.FieldOptions{Packed: proto.Bool(true)}
+		}
+		return &descriptorpb.DescriptorProto{Name: proto.String(name), Field: []*descriptorpb.FieldDescriptorProto{value}}
+	}
+	oneof := func(f *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+		f.OneofIndex = proto.Int32(0)
+		return f
+	}
+	mapOf := func(name, fieldName, entry, value string) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{
+			Name:  proto.String(name),
+			Field: []*descriptorpb.FieldDescriptorProto{field(fieldName, 1, repeated, message, name+"."+entry)},
+			NestedType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String(entry),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("key", 1, optional, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					field("value", 2, optional, message, value),
+				},
+				Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+			}},
+		}
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("tensorflow/core/example/example.proto"),
+		Package: proto.String("tensorflow"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			list("BytesList", descriptorpb.FieldDescriptorProto_TYPE_BYTES),
+			list("FloatList", descriptorpb.FieldDescriptorProto_TYPE_FLOAT),
+			list("Int64List", descriptorpb.FieldDescriptorProto_TYPE_INT64),
+			{
+				Name: proto.String("Feature"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					oneof(field("bytes_list", 1, optional, message, "BytesList")),
+					oneof(field("float_list", 2, optional, message, "FloatList")),
+					oneof(field("int64_list", 3, optional, message, "Int64List")),
+				},
+				OneofDecl: []*descriptorpb.OneofDescriptorProto{{Name: proto.String("kind")}},
+			},
+			mapOf("Features", "feature", "FeatureEntry", "Feature"),
+			{
+				Name:  proto.String("FeatureList"),
+				Field: []*descriptorpb.FieldDescriptorProto{field("feature", 1, repeated, message, "Feature")},
+			},
+			mapOf("FeatureLists", "feature_list", "FeatureListEntry", "FeatureList"),
+			{
+				Name:  proto.String("Example"),
+				Field: []*descriptorpb.FieldDescriptorProto{field("features", 1, optional, message, "Features")},
+			},
+			{
+				Name: proto.String("SequenceExample"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("context", 1, optional, message, "Features"),
+					field("feature_lists", 2, optional, message, "FeatureLists"),
+				},
+			},
+		},
+	}
+	fd, err := protodesc.NewFile(file, nil)
+	if err != nil {
+		t.Fatalf("build schema: %v", err)
+	}
+	return fd
+}
+
+// parseWithSchema decodes b as message name of tfSchema with the protobuf runtime.
+func parseWithSchema(t testing.TB, name string, b []byte) *dynamicpb.Message {
+	t.Helper()
+	m := dynamicpb.NewMessage(tfSchema(t).Messages().ByName(protoreflect.Name(name)))
+	if err := proto.Unmarshal(b, m); err != nil {
+		t.Fatalf("unmarshal %s: %v", name, err)
+	}
+	return m
+}
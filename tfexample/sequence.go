@@ -0,0 +1,159 @@
+package tfexample
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kuangyh/tfrecord"
+)
+
+// Field numbers of tf.train.SequenceExample and tf.train.FeatureLists.
+const (
+	sequenceContext      = 1
+	sequenceFeatureLists = 2
+	featureListsList     = 1
+	featureListFeature   = 1
+)
+
+// SequenceExample is a decoded tf.train.SequenceExample, bytes values alias the record it's parsed from.
+type SequenceExample struct {
+	// Context is features of the whole sequence.
+	Context map[string]Feature
+	// FeatureLists is feature lists by key, a feature per step.
+	FeatureLists map[string][]Feature
+}
+
+// ParseSequenceExample decodes a serialized tf.train.SequenceExample.
+func ParseSequenceExample(record []byte) (*SequenceExample, error) {
+	s := &SequenceExample{FeatureLists: map[string][]Feature{}}
+	var err error
+	if s.Context, err = parseFeatures(record, sequenceContext); err != nil {
+		return nil, fmt.Errorf("context: %w", err)
+	}
+	err = scanMessages(record, sequenceFeatureLists, func(lists []byte) error {
+		return scanMap(lists, featureListsList, func(key, value []byte) error {
+			steps := []Feature{}
+			err := scanMessages(value, featureListFeature, func(b []byte) error {
+				f, err := parseFeature(b)
+				steps = append(steps, f)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("feature list %q: %w", key, err)
+			}
+			s.FeatureLists[string(key)] = steps
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// featureList returns feature list key, checking all steps are of kind. Steps with no list set are empty
+// lists of any kind.
+func (s *SequenceExample) featureList(key string, kind Kind) ([]Feature, bool, error) {
+	steps, found := s.FeatureLists[key]
+	for i, f := range steps {
+		if f.Kind != kind && f.Kind != KindNone {
+			return nil, false, fmt.Errorf("feature list %q step %d is %v list, not %v", key, i, f.Kind, kind)
+		}
+	}
+	return steps, found, nil
+}
+
+// FeatureListBytes returns bytes_list values of each step of feature list key, found is false if there's no
+// such feature list. It's an error if a step has another kind.
+func (s *SequenceExample) FeatureListBytes(key string) (steps [][][]byte, found bool, err error) {
+	features, found, err := s.featureList(key, KindBytes)
+	for _, f := range features {
+		steps = append(steps, f.Bytes)
+	}
+	return steps, found, err
+}
+
+// FeatureListFloat is FeatureListBytes for float_list.
+func (s *SequenceExample) FeatureListFloat(key string) (steps [][]float32, found bool, err error) {
+	features, found, err := s.featureList(key, KindFloat)
+	for _, f := range features {
+		steps = append(steps, f.Float)
+	}
+	return steps, found, err
+}
+
+// FeatureListInt64 is FeatureListBytes for int64_list.
+func (s *SequenceExample) FeatureListInt64(key string) (steps [][]int64, found bool, err error) {
+	features, found, err := s.featureList(key, KindInt64)
+	for _, f := range features {
+		steps = append(steps, f.Int64)
+	}
+	return steps, found, err
+}
+
+// Iterator decodes each record of a tfrecord.Iterator with a decode function, like ParseSequenceExample. The
+// decoder is injected rather than built in, so the same wrapper serves any message, with this package's parsers
+// or generated protos.
+type Iterator[T any] struct {
+	it     *tfrecord.Iterator
+	decode func([]byte) (T, error)
+
+	record []byte
+	value  T
+	err    error
+}
+
+// NewIterator creates an Iterator decoding records of it with decode.
+func NewIterator[T any](it *tfrecord.Iterator, decode func([]byte) (T, error)) *Iterator[T] {
+	return &Iterator[T]{it: it, decode: decode}
+}
+
+// NewSequenceExampleIterator creates an Iterator of tf.train.SequenceExample records.
+func NewSequenceExampleIterator(it *tfrecord.Iterator) *Iterator[*SequenceExample] {
+	return NewIterator(it, ParseSequenceExample)
+}
+
+// Next moves to next record and decodes it, a decode error stops iteration.
+func (d *Iterator[T]) Next() bool {
+	var zero T
+	d.value, d.record = zero, nil
+	if d.err != nil {
+		return false
+	}
+	record, ok := d.it.NextRecord()
+	if !ok {
+		return false
+	}
+	d.value, d.err = d.decode(record.Payload)
+	if d.err != nil {
+		d.err = fmt.Errorf("record at offset %d: %w", record.Offset, d.err)
+		d.value = zero
+		return false
+	}
+	d.record = record.Payload
+	return true
+}
+
+// SequenceExample parses the current record as a tf.train.SequenceExample whatever T is, for instance to look
+// at sequence features of records decoded otherwise. Like Value, it aliases the record and is only valid until
+// next call to Next.
+func (d *Iterator[T]) SequenceExample() (*SequenceExample, error) {
+	if d.record == nil {
+		return nil, errors.New("no current record")
+	}
+	return ParseSequenceExample(d.record)
+}
+
+// Value returns the current decoded record. It's valid until next call to Next, since decoded bytes may alias
+// the underlying record.
+func (d *Iterator[T]) Value() T {
+	return d.value
+}
+
+// Err returns the decode error or error of the underlying iterator.
+func (d *Iterator[T]) Err() error {
+	if d.err != nil {
+		return d.err
+	}
+	return d.it.Err()
+}
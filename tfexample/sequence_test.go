@@ -0,0 +1,173 @@
+package tfexample
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/kuangyh/tfrecord"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// encodeSequenceExample encodes a SequenceExample with the protobuf runtime and TensorFlow's schema: context
+// feature "length" of int64, feature lists "tokens" of int64 and "words" of bytes.
+func encodeSequenceExample(t testing.TB, tokens [][]int64, words []string) []byte {
+	t.Helper()
+	schema := tfSchema(t).Messages()
+	newMessage := func(name string) *dynamicpb.Message {
+		return dynamicpb.NewMessage(schema.ByName(protoreflect.Name(name)))
+	}
+	set := func(m *dynamicpb.Message, field string, v protoreflect.Value) {
+		m.Set(m.Descriptor().Fields().ByName(protoreflect.Name(field)), v)
+	}
+	mutable := func(m *dynamicpb.Message, field string) protoreflect.Value {
+		return m.Mutable(m.Descriptor().Fields().ByName(protoreflect.Name(field)))
+	}
+	int64Feature := func(vals ...int64) *dynamicpb.Message {
+		list := newMessage("Int64List")
+		l := mutable(list, "value").List()
+		for _, v := range vals {
+			l.Append(protoreflect.ValueOfInt64(v))
+		}
+		f := newMessage("Feature")
+		set(f, "int64_list", protoreflect.ValueOfMessage(list))
+		return f
+	}
+
+	s := newMessage("SequenceExample")
+	context := newMessage("Features")
+	mutable(context, "feature").Map().Set(protoreflect.ValueOfString("length").MapKey(),
+		protoreflect.ValueOfMessage(int64Feature(int64(len(tokens)))))
+	set(s, "context", protoreflect.ValueOfMessage(context))
+
+	lists := newMessage("FeatureLists")
+	tokenList, wordList := newMessage("FeatureList"), newMessage("FeatureList")
+	for _, step := range tokens {
+		mutable(tokenList, "feature").List().Append(protoreflect.ValueOfMessage(int64Feature(step...)))
+	}
+	for _, w := range words {
+		list := newMessage("BytesList")
+		mutable(list, "value").List().Append(protoreflect.ValueOfBytes([]byte(w)))
+		f := newMessage("Feature")
+		set(f, "bytes_list", protoreflect.ValueOfMessage(list))
+		mutable(wordList, "feature").List().Append(protoreflect.ValueOfMessage(f))
+	}
+	m := mutable(lists, "feature_list").Map()
+	m.Set(protoreflect.ValueOfString("tokens").MapKey(), protoreflect.ValueOfMessage(tokenList))
+	m.Set(protoreflect.ValueOfString("words").MapKey(), protoreflect.ValueOfMessage(wordList))
+	set(s, "feature_lists", protoreflect.ValueOfMessage(lists))
+
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(s)
+	if err != nil {
+		t.Fatalf("marshal SequenceExample: %v", err)
+	}
+	return b
+}
+
+func TestSequenceExample(t *testing.T) {
+	record := encodeSequenceExample(t, [][]int64{{1, 2}, {}, {3}}, []string{"a", "b", "c"})
+	s, err := ParseSequenceExample(record)
+	if err != nil {
+		t.Fatalf("parse error %v", err)
+	}
+	if f := s.Context["length"]; f.Kind != KindInt64 || !slices.Equal(f.Int64, []int64{3}) {
+		t.Errorf("unexpected context feature %+v", f)
+	}
+	tokens, found, err := s.FeatureListInt64("tokens")
+	if err != nil || !found || len(tokens) != 3 || !slices.Equal(tokens[0], []int64{1, 2}) || len(tokens[1]) != 0 {
+		t.Errorf("unexpected tokens %v, %v, %v", tokens, found, err)
+	}
+	words, found, err := s.FeatureListBytes("words")
+	if err != nil || !found || len(words) != 3 || string(words[2][0]) != "c" {
+		t.Errorf("unexpected words %q, %v, %v", words, found, err)
+	}
+	if _, found, err := s.FeatureListFloat("missing"); found || err != nil {
+		t.Errorf("expect missing feature list not found, actual %v, %v", found, err)
+	}
+	if _, _, err := s.FeatureListFloat("words"); err == nil {
+		t.Errorf("expect error reading bytes feature list as float")
+	}
+}
+
+func TestSequenceExampleIterator(t *testing.T) {
+	var buf bytes.Buffer
+	w := tfrecord.NewWriter(&buf)
+	for i := range 3 {
+		w.Write(encodeSequenceExample(t, make([][]int64, i), nil))
+	}
+	w.Write([]byte{0xff})
+	it := NewSequenceExampleIterator(tfrecord.NewIterator(&buf, 0, true))
+	n := 0
+	for it.Next() {
+		if tokens, _, _ := it.Value().FeatureListInt64("tokens"); len(tokens) != n {
+			t.Errorf("record %d has %d steps", n, len(tokens))
+		}
+		n++
+	}
+	if n != 3 || !errors.Is(it.Err(), ErrMalformed) {
+		t.Errorf("expect decode error after 3 records, actual %d, err %v", n, it.Err())
+	}
+}
+
+// tfSequenceExamples is the TFRecord file of the SequenceExamples in testdata/gen_fixtures.py, serialized with
+// TensorFlow's example.proto.
+const tfSequenceExamples = "490000000000000036250ea30a110a0f0a066c656e67746812051a030a010212340a190a06746f6b656e73120f0a061a" +
+	"040a0201020a051a030a01030a170a05776f726473120e0a050a030a01610a050a030a01627fc255f655000000000000" +
+	"00d7df0fc10a110a0f0a066c656e67746812051a030a010312400a1e0a06746f6b656e7312140a051a030a01040a021a" +
+	"000a071a050a030506070a1e0a05776f72647312150a050a030a01630a050a030a01640a050a030a0165014704b1"
+
+func TestSequenceExampleFromTF(t *testing.T) {
+	data, err := hex.DecodeString(tfSequenceExamples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []struct {
+		tokens [][]int64
+		words  []string
+	}{
+		{[][]int64{{1, 2}, {3}}, []string{"a", "b"}},
+		{[][]int64{{4}, {}, {5, 6, 7}}, []string{"c", "d", "e"}},
+	}
+	it := NewIterator(tfrecord.NewIterator(bytes.NewReader(data), 0, true), func(b []byte) ([]byte, error) { return b, nil })
+	n := 0
+	for ; it.Next(); n++ {
+		s, err := it.SequenceExample()
+		if err != nil || n >= len(expect) {
+			t.Fatalf("record %d: parse error %v", n, err)
+		}
+		e := expect[n]
+		if length := s.Context["length"].Int64; !slices.Equal(length, []int64{int64(len(e.tokens))}) {
+			t.Errorf("record %d: unexpected context length %v", n, length)
+		}
+		tokens, _, err := s.FeatureListInt64("tokens")
+		if err != nil || !slices.EqualFunc(tokens, e.tokens, func(a, b []int64) bool { return slices.Equal(a, b) }) {
+			t.Errorf("record %d: expect tokens %v, actual %v, %v", n, e.tokens, tokens, err)
+		}
+		words, _, err := s.FeatureListBytes("words")
+		if err != nil || !slices.EqualFunc(words, e.words, func(a [][]byte, b string) bool { return len(a) == 1 && string(a[0]) == b }) {
+			t.Errorf("record %d: expect words %q, actual %q, %v", n, e.words, words, err)
+		}
+	}
+	if it.Err() != nil || n != len(expect) {
+		t.Errorf("expect %d records, actual %d, err %v", len(expect), n, it.Err())
+	}
+}
+
+func TestIteratorSequenceExample(t *testing.T) {
+	var buf bytes.Buffer
+	tfrecord.NewWriter(&buf).Write(encodeSequenceExample(t, [][]int64{{1}}, nil))
+	it := NewIterator(tfrecord.NewIterator(&buf, 0, true), func(b []byte) (int, error) { return len(b), nil })
+	if _, err := it.SequenceExample(); err == nil {
+		t.Errorf("expect error without current record")
+	}
+	if !it.Next() {
+		t.Fatalf("expect a record, err %v", it.Err())
+	}
+	if s, err := it.SequenceExample(); err != nil || len(s.FeatureLists["tokens"]) != 1 {
+		t.Errorf("unexpected sequence example %+v, %v", s, err)
+	}
+}
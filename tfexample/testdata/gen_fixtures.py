@@ -0,0 +1,58 @@
+"""Prints TFRecord files written by TensorFlow as hex, tests embed them to check wire compatibility with data
+TensorFlow produces: tfSequenceExamples in sequence_test.go and tfExample in builder_test.go.
+
+Run with TensorFlow installed:
+
+    python3 gen_fixtures.py
+"""
+
+import os
+import tempfile
+
+import tensorflow as tf
+
+
+def tfrecord_hex(records):
+    path = os.path.join(tempfile.mkdtemp(), "fixture.tfrecord")
+    with tf.io.TFRecordWriter(path) as w:
+        for r in records:
+            w.write(r)
+    with open(path, "rb") as f:
+        return f.read().hex()
+
+
+def float_feature(*values):
+    return tf.train.Feature(float_list=tf.train.FloatList(value=values))
+
+
+def int64_feature(*values):
+    return tf.train.Feature(int64_list=tf.train.Int64List(value=values))
+
+
+def bytes_feature(*values):
+    return tf.train.Feature(bytes_list=tf.train.BytesList(value=values))
+
+
+def sequence_examples():
+    # Two sequences, tokens of the second one has an empty step.
+    for tokens, words in [([[1, 2], [3]], [b"a", b"b"]), ([[4], [], [5, 6, 7]], [b"c", b"d", b"e"])]:
+        yield tf.train.SequenceExample(
+            context=tf.train.Features(feature={"length": int64_feature(len(tokens))}),
+            feature_lists=tf.train.FeatureLists(feature_list={
+                "tokens": tf.train.FeatureList(feature=[int64_feature(*step) for step in tokens]),
+                "words": tf.train.FeatureList(feature=[bytes_feature(w) for w in words]),
+            }),
+        )
+
+
+print("tfSequenceExamples", tfrecord_hex(s.SerializeToString(deterministic=True) for s in sequence_examples()))
+
+# Serialized deterministically, map keys sorted, so ExampleBuilder adding features in key order matches it byte
+# for byte.
+example = tf.train.Example(features=tf.train.Features(feature={
+    "empty": float_feature(),
+    "image": bytes_feature(b"png", b""),
+    "label": int64_feature(3, -4),
+    "score": float_feature(0.5, float("-inf")),
+}))
+print("tfExample", tfrecord_hex([example.SerializeToString(deterministic=True)]))
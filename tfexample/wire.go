@@ -0,0 +1,202 @@
+package tfexample
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ErrMalformed is error returned for records that aren't valid protobuf wire format.
+var ErrMalformed = errors.New("malformed protobuf")
+
+// Field numbers of tf.train.Example and its nested messages, see tensorflow/core/example/feature.proto and
+// example.proto.
+const (
+	exampleFeatures  protowire.Number = 1
+	featuresFeature  protowire.Number = 1
+	mapKey           protowire.Number = 1
+	mapValue         protowire.Number = 2
+	featureBytesList protowire.Number = 1
+	featureFloatList protowire.Number = 2
+	featureInt64List protowire.Number = 3
+	listValue        protowire.Number = 1
+)
+
+// scanFields calls fn with every field in b. For length-delimited fields value is the field content, for others
+// it's the encoded value.
+func scanFields(b []byte, fn func(num protowire.Number, typ protowire.Type, value []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("%w: %v", ErrMalformed, protowire.ParseError(n))
+		}
+		b = b[n:]
+		m := protowire.ConsumeFieldValue(num, typ, b)
+		if m < 0 {
+			return fmt.Errorf("%w: field %d: %v", ErrMalformed, num, protowire.ParseError(m))
+		}
+		value := b[:m]
+		if typ == protowire.BytesType {
+			value, _ = protowire.ConsumeBytes(value)
+		}
+		if err := fn(num, typ, value); err != nil {
+			return err
+		}
+		b = b[m:]
+	}
+	return nil
+}
+
+// scanMessages calls fn with content of every length-delimited field num in b, other fields are skipped.
+func scanMessages(b []byte, num protowire.Number, fn func(value []byte) error) error {
+	return scanFields(b, func(n protowire.Number, typ protowire.Type, value []byte) error {
+		if n != num || typ != protowire.BytesType {
+			return nil
+		}
+		return fn(value)
+	})
+}
+
+// scanMap calls fn with key and value of every entry of map field num in b.
+func scanMap(b []byte, num protowire.Number, fn func(key, value []byte) error) error {
+	return scanMessages(b, num, func(entry []byte) error {
+		var key, value []byte
+		err := scanFields(entry, func(n protowire.Number, typ protowire.Type, v []byte) error {
+			if typ != protowire.BytesType {
+				return nil
+			}
+			switch n {
+			case mapKey:
+				key = v
+			case mapValue:
+				value = v
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return fn(key, value)
+	})
+}
+
+// parseFeature decodes a tf.train.Feature, bytes values alias b.
+func parseFeature(b []byte) (Feature, error) {
+	var f Feature
+	err := scanFields(b, func(num protowire.Number, typ protowire.Type, list []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		var err error
+		switch num {
+		case featureBytesList:
+			f = Feature{Kind: KindBytes, Bytes: [][]byte{}}
+			err = scanMessages(list, listValue, func(v []byte) error {
+				f.Bytes = append(f.Bytes, v)
+				return nil
+			})
+		case featureFloatList:
+			f = Feature{Kind: KindFloat, Float: []float32{}}
+			err = scanFields(list, func(n protowire.Number, typ protowire.Type, v []byte) error {
+				if n != listValue {
+					return nil
+				}
+				return appendFloats(&f.Float, typ, v)
+			})
+		case featureInt64List:
+			f = Feature{Kind: KindInt64, Int64: []int64{}}
+			err = scanFields(list, func(n protowire.Number, typ protowire.Type, v []byte) error {
+				if n != listValue {
+					return nil
+				}
+				return appendInt64s(&f.Int64, typ, v)
+			})
+		}
+		return err
+	})
+	return f, err
+}
+
+// appendFloats appends a float field value to dst, packed or not.
+func appendFloats(dst *[]float32, typ protowire.Type, v []byte) error {
+	switch typ {
+	case protowire.Fixed32Type:
+		x, _ := protowire.ConsumeFixed32(v)
+		*dst = append(*dst, math.Float32frombits(x))
+	case protowire.BytesType:
+		for len(v) > 0 {
+			x, n := protowire.ConsumeFixed32(v)
+			if n < 0 {
+				return fmt.Errorf("%w: packed float list: %v", ErrMalformed, protowire.ParseError(n))
+			}
+			*dst = append(*dst, math.Float32frombits(x))
+			v = v[n:]
+		}
+	}
+	return nil
+}
+
+// appendInt64s appends an int64 field value to dst, packed or not.
+func appendInt64s(dst *[]int64, typ protowire.Type, v []byte) error {
+	switch typ {
+	case protowire.VarintType:
+		x, _ := protowire.ConsumeVarint(v)
+		*dst = append(*dst, int64(x))
+	case protowire.BytesType:
+		for len(v) > 0 {
+			x, n := protowire.ConsumeVarint(v)
+			if n < 0 {
+				return fmt.Errorf("%w: packed int64 list: %v", ErrMalformed, protowire.ParseError(n))
+			}
+			*dst = append(*dst, int64(x))
+			v = v[n:]
+		}
+	}
+	return nil
+}
+
+// appendFeature appends tf.train.Feature f to b, numeric lists are packed like TensorFlow writes them.
+func appendFeature(b []byte, f Feature) []byte {
+	var list []byte
+	switch f.Kind {
+	case KindBytes:
+		for _, v := range f.Bytes {
+			list = protowire.AppendTag(list, listValue, protowire.BytesType)
+			list = protowire.AppendBytes(list, v)
+		}
+		return appendMessage(b, featureBytesList, list)
+	case KindFloat:
+		if len(f.Float) > 0 {
+			packed := make([]byte, 0, 4*len(f.Float))
+			for _, v := range f.Float {
+				packed = protowire.AppendFixed32(packed, math.Float32bits(v))
+			}
+			list = appendMessage(list, listValue, packed)
+		}
+		return appendMessage(b, featureFloatList, list)
+	case KindInt64:
+		if len(f.Int64) > 0 {
+			var packed []byte
+			for _, v := range f.Int64 {
+				packed = protowire.AppendVarint(packed, uint64(v))
+			}
+			list = appendMessage(list, listValue, packed)
+		}
+		return appendMessage(b, featureInt64List, list)
+	}
+	return b
+}
+
+// appendMessage appends length-delimited field num with content v to b.
+func appendMessage(b []byte, num protowire.Number, v []byte) []byte {
+	return protowire.AppendBytes(protowire.AppendTag(b, num, protowire.BytesType), v)
+}
+
+// appendMapEntry appends an entry of map<string, V> field num to b, value is the encoded V.
+func appendMapEntry(b []byte, num protowire.Number, key string, value []byte) []byte {
+	entry := protowire.AppendString(protowire.AppendTag(nil, mapKey, protowire.BytesType), key)
+	entry = appendMessage(entry, mapValue, value)
+	return appendMessage(b, num, entry)
+}
@@ -0,0 +1,81 @@
+// Package tfotel traces tfrecord iteration with OpenTelemetry. It lives in its own module so the core
+// tfrecord module doesn't depend on OpenTelemetry.
+package tfotel
+
+import (
+	"context"
+
+	"github.com/kuangyh/tfrecord"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultBatchSize is the number of records covered by a span when batchSize is not positive.
+const DefaultBatchSize = 1000
+
+// WithTracer creates an iterator option that starts a span, as child of ctx, for every batchSize records read.
+// Spans carry record count and bytes read as attributes, checksum failures are added as span events. The last
+// span is ended when iteration finishes, with the iteration error recorded if any.
+func WithTracer(ctx context.Context, tracer trace.Tracer, batchSize int) tfrecord.Option {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	return tfrecord.WithMetrics(&spanMetrics{ctx: ctx, tracer: tracer, batchSize: batchSize})
+}
+
+type spanMetrics struct {
+	ctx       context.Context
+	tracer    trace.Tracer
+	batchSize int
+
+	span     trace.Span
+	records  int
+	bytes    int64
+	failures int
+}
+
+func (m *spanMetrics) startSpan() {
+	if m.span == nil {
+		_, m.span = m.tracer.Start(m.ctx, "tfrecord.ReadBatch")
+	}
+}
+
+func (m *spanMetrics) endSpan(err error) {
+	m.span.SetAttributes(
+		attribute.Int("tfrecord.records", m.records),
+		attribute.Int64("tfrecord.bytes", m.bytes),
+		attribute.Int("tfrecord.checksum_failures", m.failures),
+	)
+	if err != nil {
+		m.span.RecordError(err)
+		m.span.SetStatus(codes.Error, err.Error())
+	}
+	m.span.End()
+	m.span = nil
+	m.records, m.bytes, m.failures = 0, 0, 0
+}
+
+func (m *spanMetrics) RecordRead(frameSize int) {
+	m.startSpan()
+	m.records++
+	m.bytes += int64(frameSize)
+	if m.records >= m.batchSize {
+		m.endSpan(nil)
+	}
+}
+
+func (m *spanMetrics) ChecksumFailure() {
+	m.startSpan()
+	m.failures++
+	m.span.AddEvent("tfrecord.checksum_failure")
+}
+
+func (m *spanMetrics) IterationDone(err error) {
+	if err != nil {
+		m.startSpan()
+	}
+	if m.span != nil {
+		m.endSpan(err)
+	}
+}
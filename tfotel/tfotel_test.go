@@ -0,0 +1,86 @@
+package tfotel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kuangyh/tfrecord"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func writeRecords(t *testing.T, n int) []byte {
+	buf := &bytes.Buffer{}
+	w := tfrecord.NewWriter(buf)
+	for i := 0; i < n; i++ {
+		if _, err := w.Write([]byte("record")); err != nil {
+			t.Fatalf("failed writing, %v", err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func spanAttr(span sdktrace.ReadOnlySpan, key attribute.Key) int64 {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value.AsInt64()
+		}
+	}
+	return -1
+}
+
+func TestWithTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	it := tfrecord.NewIterator(bytes.NewReader(writeRecords(t, 5)), 16, true, WithTracer(context.Background(), tracer, 2))
+	for it.Next() {
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("read error %v", err)
+	}
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expect 3 spans, actual %d", len(spans))
+	}
+	for i, expect := range []int64{2, 2, 1} {
+		if n := spanAttr(spans[i], "tfrecord.records"); n != expect {
+			t.Errorf("span %d: expect %d records, actual %d", i, expect, n)
+		}
+		if n := spanAttr(spans[i], "tfrecord.bytes"); n != expect*(12+6+4) {
+			t.Errorf("span %d: unexpected bytes %d", i, n)
+		}
+	}
+}
+
+func TestWithTracerError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	data := writeRecords(t, 3)
+	data[len(data)-1]++
+	it := tfrecord.NewIterator(bytes.NewReader(data), 16, true, WithTracer(context.Background(), tracer, 10))
+	for it.Next() {
+	}
+	if !errors.Is(it.Err(), tfrecord.ErrChecksum) {
+		t.Fatalf("expect checksum error, actual %v", it.Err())
+	}
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expect 1 span, actual %d", len(spans))
+	}
+	span := spans[0]
+	if span.Status().Code != codes.Error {
+		t.Errorf("expect error status, actual %v", span.Status())
+	}
+	if n := spanAttr(span, "tfrecord.records"); n != 2 {
+		t.Errorf("expect 2 records, actual %d", n)
+	}
+	if n := spanAttr(span, "tfrecord.checksum_failures"); n != 1 {
+		t.Errorf("expect 1 checksum failure, actual %d", n)
+	}
+}
@@ -0,0 +1,55 @@
+// Package tfprom exports tfrecord iterator metrics to Prometheus. It's a separate module so the core tfrecord
+// module doesn't depend on the Prometheus client, pass the Metrics it creates to tfrecord.WithMetrics.
+package tfprom
+
+import (
+	"github.com/kuangyh/tfrecord"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type promMetrics struct {
+	records  prometheus.Counter
+	bytes    prometheus.Counter
+	failures prometheus.Counter
+}
+
+// NewPrometheusMetrics creates tfrecord.Metrics counting records read, bytes read and checksum failures, the
+// counters are registered to reg. Counters already registered to reg, by an earlier call for example, are
+// reused so all iterators sharing reg report to the same counters.
+func NewPrometheusMetrics(reg prometheus.Registerer) tfrecord.Metrics {
+	return &promMetrics{
+		records: register(reg, prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tfrecord_records_read_total",
+			Help: "Number of TFRecords read.",
+		})),
+		bytes: register(reg, prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tfrecord_bytes_read_total",
+			Help: "Number of TFRecord bytes read, including framing.",
+		})),
+		failures: register(reg, prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tfrecord_checksum_failures_total",
+			Help: "Number of TFRecord checksum failures.",
+		})),
+	}
+}
+
+func register(reg prometheus.Registerer, c prometheus.Counter) prometheus.Counter {
+	if err := reg.Register(c); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector.(prometheus.Counter)
+		}
+		panic(err)
+	}
+	return c
+}
+
+func (m *promMetrics) RecordRead(frameSize int) {
+	m.records.Inc()
+	m.bytes.Add(float64(frameSize))
+}
+
+func (m *promMetrics) ChecksumFailure() {
+	m.failures.Inc()
+}
+
+func (m *promMetrics) IterationDone(err error) {}
@@ -0,0 +1,40 @@
+package tfprom
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kuangyh/tfrecord"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := tfrecord.NewWriter(buf)
+	for i := 0; i < 3; i++ {
+		if _, err := w.Write([]byte("record")); err != nil {
+			t.Fatalf("failed writing, %v", err)
+		}
+	}
+	data := buf.Bytes()
+	data[len(data)-1]++
+
+	reg := prometheus.NewRegistry()
+	for i := 0; i < 2; i++ {
+		it := tfrecord.NewIterator(bytes.NewReader(data), 16, true, tfrecord.WithMetrics(NewPrometheusMetrics(reg)))
+		for it.Next() {
+		}
+	}
+
+	m := NewPrometheusMetrics(reg).(*promMetrics)
+	if v := testutil.ToFloat64(m.records); v != 4 {
+		t.Errorf("expect 4 records, actual %v", v)
+	}
+	if v := testutil.ToFloat64(m.bytes); v != 4*(12+6+4) {
+		t.Errorf("unexpected bytes %v", v)
+	}
+	if v := testutil.ToFloat64(m.failures); v != 2 {
+		t.Errorf("expect 2 checksum failures, actual %v", v)
+	}
+}
@@ -2,36 +2,135 @@
 //
 // Format spec: https://www.tensorflow.org/tutorials/load_data/tfrecord,
 // assume all numbers are little-endian although not actually defined in spec.
+//
+// Files written by Writer are readable by TensorFlow, for example in Python:
+//
+//	for record in tf.data.TFRecordDataset(["path/to/file"]):
+//	    print(record.numpy())
 package tfrecord
 
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"hash/crc32"
 	"io"
+	"math/rand"
+	"os"
+	"slices"
+	"time"
+)
+
+// Sizes of record framing, a record is laid out as HeaderSize bytes of header, the payload, then FooterSize
+// bytes of footer.
+const (
+	// LengthSize is size of the record length, uint64 in little-endian.
+	LengthSize = 8
+	// CRCSize is size of a masked CRC-32C, uint32 in little-endian.
+	CRCSize = 4
+	// HeaderSize is size of the header, record length followed by CRC of the length.
+	HeaderSize = LengthSize + CRCSize
+	// FooterSize is size of the footer, CRC of the payload.
+	FooterSize = CRCSize
 )
 
 const (
 	crcMagicNum = 0xa282ead8
 
-	lengthSize = 8
-	crcSize    = 4
-	headerSize = lengthSize + crcSize
-	footerSize = crcSize
+	// defaultBufSize is buffer size of iterators created internally.
+	defaultBufSize = 64 * 1024
+
+	maxInt64 = 1<<63 - 1
+	maxInt   = int(^uint(0) >> 1)
 )
 
+// MaxRecordSize is the largest record readers allocate a buffer for, a header claiming a larger record is an
+// ErrRecordTooLarge error instead of an allocation that could exhaust memory. It's the 2GB limit of serialized
+// protobuf, so it covers any tf.train.Example. NextStream reads larger records without buffering them.
+const MaxRecordSize = min(1<<31-1, maxInt-HeaderSize-FooterSize)
+
+// checkRecordLen returns an error if the record at offset is too large to buffer.
+func checkRecordLen(length uint64, offset int64) error {
+	if length > uint64(MaxRecordSize) {
+		return fmt.Errorf("record of %d bytes at offset %d exceeds MaxRecordSize: %w", length, offset, ErrRecordTooLarge)
+	}
+	return nil
+}
+
+// FrameSize returns number of bytes a payload of payloadLen bytes takes once framed as a record, header and
+// footer included.
+func FrameSize(payloadLen int) int64 {
+	return HeaderSize + int64(payloadLen) + FooterSize
+}
+
 // ErrChecksum is error returned when TFRecord content doesn't pass checksum.
-// It indicates data corruption or wrong file format.
+// It indicates data corruption or wrong file format. Iterator reports it wrapped in a *RecordError, check it
+// with errors.Is.
 var ErrChecksum = errors.New("checksum error in TFRecord")
 
+// ErrBufferTooSmall is error returned by NextReuse when a record doesn't fit in iterator buffer.
+var ErrBufferTooSmall = errors.New("record larger than TFRecord iterator buffer")
+
+// ErrRecordTooLarge is error returned when a record exceeds configured size limit.
+var ErrRecordTooLarge = errors.New("TFRecord too large")
+
+// ErrTruncated is error returned when TFRecord content ends in the middle of a record, in its header, payload
+// or footer. All readers report it wrapped in a *RecordError with Offset of the truncated record, never as a
+// bare io.ErrUnexpectedEOF.
+var ErrTruncated = errors.New("truncated TFRecord")
+
+// RecordError describes a failure reading the record at Offset, Err is the cause such as ErrChecksum.
+// For checksum failures StoredCRC and ComputedCRC are the CRC found in the stream and the one computed from
+// its content, comparing them across records helps telling a systematic CRC function mismatch from random
+// corruption.
+type RecordError struct {
+	Offset      int64
+	StoredCRC   uint32
+	ComputedCRC uint32
+	Err         error
+}
+
+func (e *RecordError) Error() string {
+	if e.Err == ErrChecksum {
+		return fmt.Sprintf("record at offset %d: %v, stored CRC %#08x, computed CRC %#08x",
+			e.Offset, e.Err, e.StoredCRC, e.ComputedCRC)
+	}
+	return fmt.Sprintf("record at offset %d: %v", e.Offset, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
 // see TFREcord spec.
 var crc32Table = crc32.MakeTable(crc32.Castagnoli)
 
 func checksum(p []byte) uint32 {
-	crc := crc32.Checksum(p, crc32Table)
+	return maskCRC(crc32.Checksum(p, crc32Table))
+}
+
+func maskCRC(crc uint32) uint32 {
 	return ((crc >> 15) | (crc << 17)) + crcMagicNum
 }
 
+// CRCTable returns the Castagnoli table used for TFRecord checksums, crc32.Checksum with it gives the
+// unmasked CRC-32C. The table must not be modified.
+func CRCTable() *crc32.Table {
+	return crc32Table
+}
+
+// MaskedCRC32C returns the masked CRC-32C of p, the checksum TFRecord stores for both length and data.
+func MaskedCRC32C(p []byte) uint32 {
+	return checksum(p)
+}
+
+// ChecksumMatchesTF reports whether expected is the masked CRC-32C TensorFlow stores for payload. The same
+// checksum covers the 8 length bytes in header and the record data in footer.
+func ChecksumMatchesTF(payload []byte, expected uint32) bool {
+	return checksum(payload) == expected
+}
+
 // Iterator iterates TFRecords through an io.Reader
 type Iterator struct {
 	r            io.Reader
@@ -40,106 +139,811 @@ type Iterator struct {
 	preBuf []byte
 	value  []byte
 	err    error
+	// in is where records are read from, r or a buffered reader on top of it.
+	in io.Reader
+	// offset is the position after current record, recordOffset is where the current record starts, readOffset
+	// is the number of bytes consumed from in.
+	offset       int64
+	recordOffset int64
+	readOffset   int64
+	// ordinal is the number of records read.
+	ordinal int64
+	header  [HeaderSize]byte
+	footer  [FooterSize]byte
+
+	autoClose bool
+	metrics   []Metrics
+	finished  bool
+	// doneReported stays true once IterationDone is called, unlike finished ClearErr doesn't reset it.
+	doneReported bool
+	crcChunks    int
+
+	keepCorrupt bool
+	crcValid    bool
+	dataCRC     uint32
+	valueOwned  bool
+	record      Record
+	noFooter    bool
+	raw         bool
+	endianDiag  bool
+	part        partialFrame
+	resumable   bool
+	// transient is true when err is a transient error the next read resumes from.
+	transient bool
+	// clearable tells whether and how ClearErr can recover from err.
+	clearable clearMode
+	// crcChecked is true when dataCRC of current record was checked against its payload and matched.
+	crcChecked bool
+
+	sampleRate   float64
+	sampleRNG    *rand.Rand
+	sampled      int
+	sampleFailed int
+	seqPrefix    bool
+	sequence     uint64
+	// expectCount is expected number of records, -1 if not set.
+	expectCount int64
+
+	retryAttempts int
+	retryBackoff  time.Duration
+	retryable     func(error) bool
+	// stream is payload reader returned by NextStream not drained yet.
+	stream      *payloadStream
+	readTimeout time.Duration
+	maxBuffer   int64
+	oversize    OversizePolicy
+	onRealloc   func(recordLen uint64)
+	// trace is where Trace logs records.
+	trace io.Writer
+
+	allocator Allocator
+	// preBufAllocated is true when preBuf is from allocator, borrowed is buffer of current oversize record from
+	// allocator.
+	preBufAllocated bool
+	borrowed        []byte
+	closed          bool
+	compression     CompressionType
+	decompressor    *decompressReader
+	// baseOffset is readOffset the iterator started from.
+	baseOffset  int64
+	skipPadding bool
+	// adaptive grows preBuf to fit records, for bufSize <= 0.
+	adaptive bool
+
+	readerBuf int
+	readahead int
+	ahead     []frame
+	aheadBuf  []frame
+	aheadErr  error
 }
 
 // NewIterator creates a Iterator. Iterator pre-allocates and reuse buffer to avoid frequent buffer allocation,
 // bufSize should be set to upper-bound of expected common record size. when checkDataCRC is true, check CRC of
 // data content, this is the recommend setup because checking CRC of data won't be performance bottleneck in most cases.
-func NewIterator(r io.Reader, bufSize int64, checkDataCRC bool) *Iterator {
+// With bufSize <= 0 the buffer starts empty and grows to fit the largest record read so far, regardless of
+// WithOversizePolicy, so uniform records stop allocating after the first one.
+func NewIterator(r io.Reader, bufSize int64, checkDataCRC bool, opts ...Option) *Iterator {
 	var buf []byte
 	if bufSize > 0 {
 		buf = make([]byte, bufSize)
 	}
-	return &Iterator{
+	it := &Iterator{
 		r:            r,
+		in:           r,
 		checkDataCRC: checkDataCRC,
 		preBuf:       buf,
+		expectCount:  -1,
+		adaptive:     bufSize <= 0,
+	}
+	for _, opt := range opts {
+		opt(it)
 	}
+	if it.maxBuffer > 0 && int64(len(it.preBuf)) > it.maxBuffer {
+		it.preBuf = it.preBuf[:it.maxBuffer:it.maxBuffer]
+	}
+	it.initCompression()
+	it.initReadahead()
+	it.initRetry()
+	return it
+}
+
+// NewIteratorAt creates an Iterator reading r sequentially from start, for random access only sources like
+// mmaped files or HTTP ranges, without an index. Offsets it reports, in errors and State, are positions in r.
+// Otherwise it behaves the same as an Iterator over io.Reader.
+func NewIteratorAt(r io.ReaderAt, start int64, bufSize int64, checkDataCRC bool, opts ...Option) *Iterator {
+	it := NewIterator(io.NewSectionReader(r, start, maxInt64-start), bufSize, checkDataCRC, opts...)
+	it.offset, it.readOffset, it.baseOffset = start, start, start
+	return it
 }
 
 // Next reads in next record from underlying reader
 func (it *Iterator) Next() bool {
-	if it.err != nil {
+	if it.next(false) {
+		return true
+	}
+	if !it.transient {
+		it.finish()
+	}
+	return false
+}
+
+// NextReuse reads in next record and returns it, it's the allocation free variant of Next. The returned record
+// always aliases the iterator buffer, which is only valid until next read. As long as records fit in bufSize,
+// NextReuse never allocates. A record larger than bufSize stops iteration, NextReuse returns (nil, false) and Err
+// returns an error wrapping ErrBufferTooSmall, caller should retry with a larger bufSize. With bufSize <= 0 the
+// buffer grows to fit instead, NextReuse only allocates for records larger than any seen so far. WithReadahead
+// keeps the buffer contract, but its buffered copies still cost an allocation per record.
+func (it *Iterator) NextReuse() ([]byte, bool) {
+	if it.next(true) {
+		return it.value, true
+	}
+	if !it.transient {
+		it.finish()
+	}
+	return nil, false
+}
+
+// Record is a record with its framing metadata.
+type Record struct {
+	// Offset is the position of the record's header in stream.
+	Offset int64
+	// Length is the payload length.
+	Length uint64
+	// DataCRC is the masked data CRC stored in the record's footer, as is even when data CRC isn't checked.
+	DataCRC uint32
+	Payload []byte
+}
+
+// NextRecord reads in next record like Next and returns it with its metadata. The returned Record is reused
+// and its Payload aliases the iterator buffer, both are only valid until next read.
+func (it *Iterator) NextRecord() (*Record, bool) {
+	if !it.Next() {
+		return nil, false
+	}
+	it.record = Record{
+		Offset:  it.recordOffset,
+		Length:  uint64(len(it.value)),
+		DataCRC: it.dataCRC,
+		Payload: it.value,
+	}
+	return &it.record, true
+}
+
+// finish runs once iteration reaches its terminal state.
+func (it *Iterator) finish() {
+	if it.finished {
+		return
+	}
+	it.finished = true
+	if c, ok := it.r.(io.Closer); ok && it.autoClose {
+		if err := c.Close(); err != nil && it.err == nil {
+			it.err = err
+		}
+	}
+	if it.doneReported {
+		return
+	}
+	it.doneReported = true
+	for _, m := range it.metrics {
+		m.IterationDone(it.err)
+	}
+}
+
+func (it *Iterator) next(reuseOnly bool) bool {
+	if it.closed {
 		return false
 	}
-	withError := func(err error) bool {
-		it.err = err
+	if it.transient {
+		it.err, it.aheadErr, it.transient = nil, nil, false
+	}
+	if !it.drainStream() {
 		return false
 	}
-
-	it.value = nil
-	header := [headerSize]byte{}
-	if _, err := io.ReadFull(it.r, header[:]); err != nil {
-		if err == io.EOF {
+	it.value, it.valueOwned = nil, false
+	var (
+		f   frame
+		err error
+	)
+	for {
+		if it.readahead > 0 {
+			f, err = it.nextBuffered()
+		} else {
+			f, err = it.readFrame(reuseOnly, false)
+		}
+		if err != nil || !it.skipPadding || !isPadding(f.record) {
+			break
+		}
+	}
+	if err == nil && reuseOnly && it.readahead > 0 {
+		f, err = it.reuseBuffered(f)
+	}
+	if err != nil {
+		if err != io.EOF {
+			it.err = err
+			it.transient = it.resumable && it.isTransient(err)
+		} else {
+			it.err = it.checkCount()
+		}
+		return false
+	}
+	it.value, it.recordOffset, it.crcValid, it.dataCRC = f.record, f.offset, f.crcValid, f.dataCRC
+	it.crcChecked = f.crcChecked
+	it.valueOwned = f.owned
+	if it.trace != nil {
+		if err := it.traceRecord(f.offset, len(f.record)); err != nil {
+			it.value, it.err = nil, err
 			return false
 		}
-		return withError(err)
 	}
-	recordLen := binary.LittleEndian.Uint64(header[:lengthSize])
-	lenCRC := binary.LittleEndian.Uint32(header[lengthSize:])
-	if crc := checksum(header[:lengthSize]); crc != lenCRC {
-		return withError(ErrChecksum)
+	if it.seqPrefix {
+		if err := it.stripSequence(); err != nil {
+			it.value, it.err = nil, err
+			return false
+		}
+	}
+	it.offset = f.offset + int64(it.headerLen()+len(f.record)+it.footerLen())
+	it.ordinal++
+	for _, m := range it.metrics {
+		m.RecordRead(it.headerLen() + len(f.record) + it.footerLen())
 	}
+	return true
+}
 
-	var record []byte
-	if recordLen > uint64(len(it.preBuf)) {
-		record = make([]byte, recordLen)
-	} else {
-		record = it.preBuf[:recordLen]
+// frame is a record read from stream.
+type frame struct {
+	record     []byte
+	offset     int64
+	crcValid   bool
+	crcChecked bool
+	dataCRC    uint32
+	// owned is false when record aliases preBuf.
+	owned bool
+}
+
+// readFrame reads next record from stream, it returns io.EOF at clean end of stream. The record aliases preBuf
+// unless owned is true or it doesn't fit.
+func (it *Iterator) readFrame(reuseOnly, owned bool) (frame, error) {
+	withError := func(err error) (frame, error) {
+		if it.readTimeout > 0 && errors.Is(err, os.ErrDeadlineExceeded) {
+			err = fmt.Errorf("read timeout after %v: %w", it.readTimeout, err)
+		}
+		return frame{}, err
+	}
+
+	it.clearable = notClearable
+	offset, recordLen, err := it.readHeader()
+	if err != nil {
+		return withError(err)
+	}
+	p := &it.part
+	if p.record == nil {
+		if p.record, err = it.recordBuf(recordLen, offset, reuseOnly, owned); err != nil {
+			it.clearable = clearSkipRecord
+			return withError(err)
+		}
 	}
-	if _, err := io.ReadFull(it.r, record); err != nil {
+	record := p.record
+	if err := readPart(it.in, record, &p.recordN); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = &RecordError{Offset: offset, Err: ErrTruncated}
+		}
 		return withError(err)
 	}
-	var footer [footerSize]byte
-	if _, err := io.ReadFull(it.r, footer[:]); err != nil {
+	if err := readPart(it.in, it.footer[:it.footerLen()], &p.footerN); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			// writer likely stopped between payload and footer.
+			err = &RecordError{Offset: offset, Err: ErrTruncated}
+		}
 		return withError(err)
 	}
-	if it.checkDataCRC {
-		dataCRC := binary.LittleEndian.Uint32(footer[:])
-		if crc := checksum(record); crc != dataCRC {
-			return withError(ErrChecksum)
+	it.part = partialFrame{}
+	it.readOffset += int64(recordLen) + int64(it.footerLen())
+	dataCRC := binary.LittleEndian.Uint32(it.footer[:])
+	f := frame{record: record, offset: offset, crcValid: true, dataCRC: dataCRC, owned: !it.aliasesBuf(record) && it.borrowed == nil}
+	if it.footerLen() > 0 && it.shouldCheckCRC() {
+		if crc := it.dataChecksum(record); crc != dataCRC {
+			it.checksumFailure()
+			it.sampleFailed++
+			if !it.keepCorrupt {
+				it.clearable = clearAtBoundary
+				return withError(&RecordError{Offset: offset, StoredCRC: dataCRC, ComputedCRC: crc, Err: ErrChecksum})
+			}
+			f.crcValid = false
+		} else {
+			f.crcChecked = true
 		}
 	}
-	it.value = record
-	return true
+	return f, nil
 }
 
-// Err returns any error stopping Next(), io.EOF is not considered error
+// partialFrame is progress reading a frame, kept across reads failing midway so they can be resumed.
+type partialFrame struct {
+	headerN int
+	length  uint64
+	record  []byte
+	recordN int
+	footerN int
+}
+
+// readPart reads into p[*n:] and adds number of bytes read to n.
+func readPart(r io.Reader, p []byte, n *int) error {
+	read, err := io.ReadFull(r, p[*n:])
+	*n += read
+	return err
+}
+
+// readHeader sets read deadline if configured and reads next record header, it returns offset and length of
+// the record, or io.EOF at clean end of stream. When resuming a frame whose header is read, it returns the
+// header read before.
+func (it *Iterator) readHeader() (int64, uint64, error) {
+	p := &it.part
+	headerLen := it.headerLen()
+	if p.headerN == headerLen {
+		return it.readOffset - int64(headerLen), p.length, nil
+	}
+	if it.readTimeout > 0 {
+		if dr, ok := it.r.(deadlineReader); ok {
+			if err := dr.SetReadDeadline(time.Now().Add(it.readTimeout)); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+	offset := it.readOffset
+	if err := readPart(it.in, it.header[:headerLen], &p.headerN); err != nil {
+		if err == io.EOF && p.headerN == 0 {
+			return 0, 0, io.EOF
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			// writer likely stopped before committing the record length.
+			return 0, 0, &RecordError{Offset: offset, Err: ErrTruncated}
+		}
+		return 0, 0, err
+	}
+	it.readOffset += int64(headerLen)
+	if it.raw {
+		p.length = binary.LittleEndian.Uint64(it.header[:])
+		return offset, p.length, nil
+	}
+	recordLen, err := parseHeader(it.header[:])
+	if err != nil {
+		it.checksumFailure()
+		return 0, 0, &RecordError{
+			Offset:      offset,
+			StoredCRC:   binary.LittleEndian.Uint32(it.header[LengthSize:]),
+			ComputedCRC: checksum(it.header[:LengthSize]),
+			Err:         it.diagnoseHeader(err),
+		}
+	}
+	p.length = recordLen
+	return offset, recordLen, nil
+}
+
+// aliasesBuf reports whether b is backed by preBuf.
+func (it *Iterator) aliasesBuf(b []byte) bool {
+	return cap(b) > 0 && cap(it.preBuf) > 0 && &b[:1][0] == &it.preBuf[:1][0]
+}
+
+// recordBuf returns buffer to read record of length n into, following oversize policy and buffer limit.
+func (it *Iterator) recordBuf(n uint64, offset int64, reuseOnly, owned bool) ([]byte, error) {
+	it.releaseBorrowed()
+	if err := checkRecordLen(n, offset); err != nil {
+		return nil, err
+	}
+	// A reader knowing how much is left, like bytes.Reader, tells a corrupt length before allocating for it.
+	if l, ok := it.in.(interface{ Len() int }); ok && !it.resumable && n+uint64(it.footerLen()) > uint64(l.Len()) {
+		return nil, &RecordError{Offset: offset, Err: ErrTruncated}
+	}
+	if n <= uint64(len(it.preBuf)) && it.preBuf != nil && !owned {
+		return it.preBuf[:n], nil
+	}
+	policy := it.oversize
+	switch {
+	case owned:
+		policy = OversizeAllocate
+	case it.adaptive:
+		policy = OversizeGrow
+	case reuseOnly:
+		policy = OversizeError
+	}
+	if n > uint64(len(it.preBuf)) {
+		limit := it.maxBuffer
+		if policy == OversizeAllocate {
+			limit -= int64(len(it.preBuf))
+		}
+		switch {
+		case policy == OversizeError:
+			return nil, fmt.Errorf("record of %d bytes with buffer of %d bytes: %w", n, len(it.preBuf), ErrBufferTooSmall)
+		case it.maxBuffer > 0 && n > uint64(limit):
+			return nil, fmt.Errorf("record of %d bytes at offset %d exceeds total buffer limit of %d bytes with %d bytes buffer: %w",
+				n, offset, it.maxBuffer, len(it.preBuf), ErrRecordTooLarge)
+		}
+		if it.onRealloc != nil {
+			it.onRealloc(n)
+		}
+		if policy == OversizeGrow {
+			it.growBuf(int(n))
+			return it.preBuf, nil
+		}
+	}
+	if it.allocator != nil && !owned && n > 0 {
+		it.borrowed = it.allocator.Get(int(n))
+		return it.borrowed, nil
+	}
+	// preBuf is nil until the first non-empty record when bufSize <= 0, make sure an empty record still gets a
+	// non-nil value.
+	return make([]byte, n), nil
+}
+
+// ValueOwned reports whether current record is allocated on its own rather than in the iterator buffer, which
+// happens for records larger than the buffer. An owned record is never overwritten by later reads, caller can
+// retain it without copying. Records from WithAllocator are not owned, they go back to the allocator on next
+// read.
+func (it *Iterator) ValueOwned() bool {
+	return it.valueOwned
+}
+
+// Err returns any error stopping Next(), io.EOF is not considered error. Iteration is always strict about end
+// of stream: it only stops cleanly at a frame boundary, trailing bytes not forming a valid frame are an error.
 func (it *Iterator) Err() error {
 	return it.err
 }
 
-// Value returns the current value, returns nil when iterator not in valid state
+// LastCRCValid reports whether current record passed data CRC check, it's only false for records returned
+// under WithCorruptRecords. Records are considered valid when data CRC is not checked.
+func (it *Iterator) LastCRCValid() bool {
+	return it.crcValid
+}
+
+// Value returns the current value, returns nil when iterator not in valid state. A valid empty record is
+// returned as a non-nil zero-length slice, so nil always means there's no current record.
 func (it *Iterator) Value() []byte {
 	return it.value
 }
 
+// Scan is alias of Next for the bufio.Scanner idiom, Next is the canonical name.
+func (it *Iterator) Scan() bool {
+	return it.Next()
+}
+
+// Bytes is alias of Value for the bufio.Scanner idiom, Value is the canonical name.
+func (it *Iterator) Bytes() []byte {
+	return it.Value()
+}
+
+// WriteTo implements io.WriterTo, it writes all remaining records to w and returns number of bytes written.
+// w is a raw sink, each record is framed again with freshly computed CRCs, so with data CRC checking disabled
+// it also repairs corrupt data CRCs. As special case when w is a *Writer, records are passed to it as is since
+// it frames them itself. The returned count is bytes the Writer emitted in both cases, padding and framing of
+// its options included.
+func (it *Iterator) WriteTo(w io.Writer) (int64, error) {
+	tw, ok := w.(*Writer)
+	if !ok {
+		tw = NewWriter(w)
+	}
+	start := tw.offset
+	for it.Next() {
+		if _, err := tw.Write(it.Value()); err != nil {
+			return tw.offset - start, err
+		}
+	}
+	return tw.offset - start, it.Err()
+}
+
+// drainProgressInterval is number of records between DrainTo progress calls.
+const drainProgressInterval = 1000
+
+// DrainTo writes all remaining records to w, framed again with fresh CRCs, and returns the number of records
+// copied. progress, if not nil, is called with the running count every 1000 records and once at the end. It
+// stops on the first read or write error.
+func (it *Iterator) DrainTo(w *Writer, progress func(n int)) (int, error) {
+	n := 0
+	for it.Next() {
+		if _, err := w.Write(it.Value()); err != nil {
+			return n, err
+		}
+		n++
+		if progress != nil && n%drainProgressInterval == 0 {
+			progress(n)
+		}
+	}
+	if progress != nil && n%drainProgressInterval != 0 {
+		progress(n)
+	}
+	return n, it.Err()
+}
+
 // NewWriter creates a TFRecord writer on top of w
-func NewWriter(w io.Writer) *Writer {
-	return &Writer{w: w}
+func NewWriter(w io.Writer, opts ...WriterOption) *Writer {
+	tw := &Writer{w: w, scratch: make([]byte, HeaderSize+FooterSize)}
+	for _, opt := range opts {
+		opt(tw)
+	}
+	tw.initFlush()
+	return tw
 }
 
-// Writer implements io.Writer that writes TFRecord
+// Writer implements io.Writer that writes TFRecord, it's not safe for concurrent use.
 type Writer struct {
 	w io.Writer
+	// scratch is reused across Write calls for framing bytes, and whole frames of small records.
+	scratch []byte
+
+	seqPrefix bool
+	seq       uint64
+	seqBuf    [sequenceSize]byte
+	noFooter  bool
+	raw       bool
+	// offset is number of bytes written, align is alignment of payloads set by WithAlignment.
+	offset int64
+	align  int
+	padBuf []byte
+	// maxSize is record size limit set by WithMaxWriteSize, 0 if not set.
+	maxSize   int
+	autoFlush int
+	sync      bool
 }
 
+// singleWriteMax is the largest record written as a single frame through scratch, larger ones are written
+// in place with separate writes of header, payload and footer.
+const singleWriteMax = 4096
+
 // Write implements io.Write
 func (w *Writer) Write(record []byte) (n int, err error) {
-	header := [headerSize]byte{}
-	binary.LittleEndian.PutUint64(header[:lengthSize], uint64(len(record)))
-	binary.LittleEndian.PutUint32(header[lengthSize:], checksum(header[:lengthSize]))
-	if _, err := w.w.Write(header[:]); err != nil {
+	if err := w.checkSize(uint64(len(record))); err != nil {
 		return 0, err
 	}
-
-	if _, err := w.w.Write(record); err != nil {
+	if err := w.pad(); err != nil {
 		return 0, err
 	}
-	var footer [footerSize]byte
-	binary.LittleEndian.PutUint32(footer[:], checksum(record))
-	if _, err := w.w.Write(footer[:]); err != nil {
+	prefix := w.prefix()
+	size := len(prefix) + len(record)
+	var crc uint32
+	if w.footerLen() > 0 {
+		crc = maskCRC(crc32.Update(crc32.Checksum(prefix, crc32Table), crc32Table, record))
+	}
+	if size <= singleWriteMax {
+		err = w.writeSingle(prefix, record, crc)
+	} else {
+		err = w.writeInPlace(prefix, record, crc)
+	}
+	if err != nil {
 		return 0, err
 	}
+	w.seq++
+	w.offset += int64(w.headerLen() + size + w.footerLen())
 	return len(record), nil
 }
+
+// writeSingle copies framed record into scratch and writes it in one go, saving writes to the destination.
+func (w *Writer) writeSingle(prefix, record []byte, crc uint32) error {
+	size := len(prefix) + len(record)
+	if cap(w.scratch) < HeaderSize+size+FooterSize {
+		w.scratch = make([]byte, HeaderSize+singleWriteMax+FooterSize)
+	}
+	header := w.putHeader(w.scratch, uint64(size))
+	frame := w.scratch[:len(header)+size+FooterSize]
+	copy(frame[len(header):], prefix)
+	copy(frame[len(header)+len(prefix):], record)
+	binary.LittleEndian.PutUint32(frame[len(header)+size:], crc)
+	return writeFull(w.w, frame[:len(header)+size+w.footerLen()])
+}
+
+// writeInPlace writes header, payload and footer separately, without copying payload.
+func (w *Writer) writeInPlace(prefix, record []byte, crc uint32) error {
+	header := w.putHeader(w.scratch, uint64(len(prefix)+len(record)))
+	footer := w.scratch[HeaderSize : HeaderSize+FooterSize]
+	if err := writeFull(w.w, header); err != nil {
+		return err
+	}
+	if err := writeFull(w.w, prefix); err != nil {
+		return err
+	}
+	if err := writeFull(w.w, record); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(footer, crc)
+	return writeFull(w.w, footer[:w.footerLen()])
+}
+
+// writeFromChunkSize is the size of chunks WriteFrom streams payload in.
+const writeFromChunkSize = 32 * 1024
+
+// WriteFrom writes a record of length bytes streamed from src, CRC is computed incrementally so the payload is
+// never held in memory as a whole. It returns number of payload bytes written. If src yields fewer than length
+// bytes it fails with io.ErrUnexpectedEOF, the partial record already written leaves the output truncated.
+func (w *Writer) WriteFrom(src io.Reader, length uint64) (int64, error) {
+	if err := w.checkSize(length); err != nil {
+		return 0, err
+	}
+	if err := w.pad(); err != nil {
+		return 0, err
+	}
+	prefix := w.prefix()
+	header := w.putHeader(w.scratch, uint64(len(prefix))+length)
+	footer := w.scratch[HeaderSize : HeaderSize+FooterSize]
+	if err := writeFull(w.w, header); err != nil {
+		return 0, err
+	}
+	if err := writeFull(w.w, prefix); err != nil {
+		return 0, err
+	}
+	chunk := make([]byte, min(length, writeFromChunkSize))
+	crc := crc32.Checksum(prefix, crc32Table)
+	var n int64
+	for rest := length; rest > 0; {
+		p := chunk[:min(rest, uint64(len(chunk)))]
+		if _, err := io.ReadFull(src, p); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return n, fmt.Errorf("record of %d bytes, source ends after %d bytes: %w", length, n, err)
+		}
+		if w.footerLen() > 0 {
+			crc = crc32.Update(crc, crc32Table, p)
+		}
+		if err := writeFull(w.w, p); err != nil {
+			return n, err
+		}
+		n += int64(len(p))
+		rest -= uint64(len(p))
+	}
+	binary.LittleEndian.PutUint32(footer, maskCRC(crc))
+	if err := writeFull(w.w, footer[:w.footerLen()]); err != nil {
+		return n, err
+	}
+	w.seq++
+	w.offset += int64(len(header)+len(prefix)+w.footerLen()) + n
+	return n, nil
+}
+
+// WriteReadAll reads src until EOF and writes what's read as one record, returning the payload size. Unlike
+// WriteFrom, which streams a payload of known length, the whole payload is buffered, in a buffer the writer
+// keeps and reuses across calls so it holds on to memory of the largest payload written this way. With
+// WithMaxWriteSize it fails with ErrRecordTooLarge as soon as src yields more than the limit, nothing is
+// written then.
+func (w *Writer) WriteReadAll(src io.Reader) (int, error) {
+	prefix := w.prefix()
+	buf := slices.Grow(w.scratch[:0], HeaderSize+len(prefix)+FooterSize+512)
+	buf = buf[:cap(buf)]
+	n := HeaderSize + copy(buf[HeaderSize:], prefix)
+	for {
+		if len(buf)-n < FooterSize+512 {
+			buf = slices.Grow(buf[:n], max(FooterSize+512, n))
+			buf = buf[:cap(buf)]
+		}
+		m, err := src.Read(buf[n : len(buf)-FooterSize])
+		n += m
+		w.scratch = buf
+		if err := w.checkSize(uint64(n - HeaderSize - len(prefix))); err != nil {
+			return 0, err
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	if err := w.pad(); err != nil {
+		return 0, err
+	}
+	payload := buf[HeaderSize:n]
+	header := w.putHeader(buf[HeaderSize-w.headerLen():HeaderSize], uint64(len(payload)))
+	if w.footerLen() > 0 {
+		binary.LittleEndian.PutUint32(buf[n:], checksum(payload))
+	}
+	frame := buf[HeaderSize-len(header) : n+w.footerLen()]
+	if err := writeFull(w.w, frame); err != nil {
+		return 0, err
+	}
+	w.seq++
+	w.offset += int64(len(frame))
+	return len(payload) - len(prefix), nil
+}
+
+// WriteFramed writes payload framed with the given masked length and data CRCs as is, without computing any
+// checksum, for re-copying frames bit for bit as fast as possible. It's a power-user primitive: a wrong CRC
+// produces a file failing validation, for TensorFlow as well. Payload is written as is with WithSequencePrefix,
+// since a prefix would invalidate dataCRC. lengthCRC is ignored with WithRawWrites, dataCRC with WithoutDataCRC
+// or WithRawWrites.
+func (w *Writer) WriteFramed(payload []byte, lengthCRC, dataCRC uint32) (int, error) {
+	if err := w.checkSize(uint64(len(payload))); err != nil {
+		return 0, err
+	}
+	if err := w.pad(); err != nil {
+		return 0, err
+	}
+	size := w.headerLen() + len(payload) + w.footerLen()
+	if len(payload) > singleWriteMax {
+		var frame [HeaderSize + FooterSize]byte
+		header, footer := frame[:w.headerLen()], frame[HeaderSize:HeaderSize+w.footerLen()]
+		putFramedHeader(header, uint64(len(payload)), lengthCRC)
+		binary.LittleEndian.PutUint32(frame[HeaderSize:], dataCRC)
+		for _, p := range [][]byte{header, payload, footer} {
+			if err := writeFull(w.w, p); err != nil {
+				return 0, err
+			}
+		}
+	} else {
+		if cap(w.scratch) < HeaderSize+singleWriteMax+FooterSize {
+			w.scratch = make([]byte, HeaderSize+singleWriteMax+FooterSize)
+		}
+		frame := w.scratch[:size]
+		putFramedHeader(frame[:w.headerLen()], uint64(len(payload)), lengthCRC)
+		copy(frame[w.headerLen():], payload)
+		if w.footerLen() > 0 {
+			binary.LittleEndian.PutUint32(frame[size-FooterSize:], dataCRC)
+		}
+		if err := writeFull(w.w, frame); err != nil {
+			return 0, err
+		}
+	}
+	w.seq++
+	w.offset += int64(size)
+	return len(payload), nil
+}
+
+// putFramedHeader puts length and lengthCRC into header, lengthCRC is left out of raw headers.
+func putFramedHeader(header []byte, length uint64, lengthCRC uint32) {
+	binary.LittleEndian.PutUint64(header, length)
+	if len(header) == HeaderSize {
+		binary.LittleEndian.PutUint32(header[LengthSize:], lengthCRC)
+	}
+}
+
+// reset makes w write to dst as if newly created, keeping its scratch buffer.
+func (w *Writer) reset(dst io.Writer) {
+	for i := range w.scratch {
+		w.scratch[i] = 0
+	}
+	*w = Writer{w: dst, scratch: w.scratch, padBuf: w.padBuf}
+}
+
+// ValidateLengthHeader checks CRC of a record header. A header is 12 bytes: record length as uint64 in
+// little-endian, followed by the masked CRC-32C of exactly those 8 length bytes as uint32 in little-endian.
+// It returns ErrChecksum when the CRC doesn't match.
+func ValidateLengthHeader(header []byte) error {
+	if len(header) != HeaderSize {
+		return fmt.Errorf("record header of %d bytes, expect %d", len(header), HeaderSize)
+	}
+	lenCRC := binary.LittleEndian.Uint32(header[LengthSize:])
+	if crc := checksum(header[:LengthSize]); crc != lenCRC {
+		return ErrChecksum
+	}
+	return nil
+}
+
+// parseHeader returns record length in header after checking its CRC.
+func parseHeader(header []byte) (uint64, error) {
+	if err := ValidateLengthHeader(header); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(header[:LengthSize]), nil
+}
+
+// putHeader fills header with record length and its CRC.
+func putHeader(header []byte, length uint64) {
+	binary.LittleEndian.PutUint64(header[:LengthSize], length)
+	binary.LittleEndian.PutUint32(header[LengthSize:], checksum(header[:LengthSize]))
+}
+
+// putFooter fills footer with CRC of record.
+func putFooter(footer []byte, record []byte) {
+	binary.LittleEndian.PutUint32(footer, checksum(record))
+}
+
+// writeFull writes all of p to w, retrying on short writes. A writer making no progress without error
+// fails with io.ErrShortWrite instead of looping forever.
+func writeFull(w io.Writer, p []byte) error {
+	for len(p) > 0 {
+		n, err := w.Write(p)
+		if err != nil {
+			return err
+		}
+		if n <= 0 {
+			return io.ErrShortWrite
+		}
+		p = p[n:]
+	}
+	return nil
+}
@@ -2,7 +2,14 @@ package tfrecord
 
 import (
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
 	"os"
+	"strconv"
 	"testing"
 )
 
@@ -58,3 +65,707 @@ func TestReadFromTF(t *testing.T) {
 		t.Errorf("unmatched read content, expect %s, acutal %s", expect, out)
 	}
 }
+
+func TestEmptyRecord(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	for _, r := range []string{"", "x", ""} {
+		if _, err := w.Write([]byte(r)); err != nil {
+			t.Fatalf("failed writing %q, %v", r, err)
+		}
+	}
+	if expect := 3*(HeaderSize+FooterSize) + 1; buf.Len() != expect {
+		t.Fatalf("expect %d bytes written, actual %d", expect, buf.Len())
+	}
+
+	for _, bufSize := range []int64{0, 16} {
+		it := NewIterator(bytes.NewReader(buf.Bytes()), bufSize, true)
+		if it.Value() != nil {
+			t.Errorf("expect nil value before first Next")
+		}
+		var read []string
+		for it.Next() {
+			if it.Value() == nil {
+				t.Errorf("bufSize %d: expect non-nil value for record %d", bufSize, len(read))
+			}
+			read = append(read, string(it.Value()))
+		}
+		if err := it.Err(); err != nil {
+			t.Fatalf("bufSize %d: read error %v", bufSize, err)
+		}
+		if len(read) != 3 || read[0] != "" || read[1] != "x" || read[2] != "" {
+			t.Errorf("bufSize %d: unmatched read %q", bufSize, read)
+		}
+		if it.Value() != nil {
+			t.Errorf("bufSize %d: expect nil value after iteration", bufSize)
+		}
+	}
+
+	// The footer of an empty payload is the masked CRC of no bytes, corrupting it must be detected.
+	data := append([]byte(nil), buf.Bytes()[:HeaderSize+FooterSize]...)
+	data[HeaderSize]++
+	it := NewIterator(bytes.NewReader(data), 16, true)
+	if it.Next() {
+		t.Errorf("expect corrupt empty record to fail")
+	}
+	if !errors.Is(it.Err(), ErrChecksum) {
+		t.Errorf("expect checksum error, actual %v", it.Err())
+	}
+}
+
+// shortWriter writes at most 3 bytes per call without error.
+type shortWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > 3 {
+		p = p[:3]
+	}
+	return w.buf.Write(p)
+}
+
+func TestShortWrite(t *testing.T) {
+	records := []string{"Hello", "", "World!"}
+	sw := &shortWriter{}
+	w := NewWriter(sw)
+	for _, r := range records {
+		if n, err := w.Write([]byte(r)); err != nil || n != len(r) {
+			t.Fatalf("failed writing %s, n %d, err %v", r, n, err)
+		}
+	}
+	it := NewIterator(bytes.NewReader(sw.buf.Bytes()), 16, true)
+	var read []string
+	for it.Next() {
+		read = append(read, string(it.Value()))
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("read error %v", err)
+	}
+	if len(read) != len(records) {
+		t.Fatalf("unmatched read len, expect %d, actual %d", len(records), len(read))
+	}
+	for i, v := range records {
+		if v != read[i] {
+			t.Errorf("unmatched read value idx %d, expect %s, actual %s", i, v, read[i])
+		}
+	}
+}
+
+func TestFrameSize(t *testing.T) {
+	for _, size := range []int{0, 5, 100000} {
+		buf := &bytes.Buffer{}
+		NewWriter(buf).Write(make([]byte, size))
+		if n := FrameSize(size); n != int64(buf.Len()) {
+			t.Errorf("payload of %d bytes, expect frame size %d, actual %d", size, buf.Len(), n)
+		}
+	}
+}
+
+func TestSingleWrite(t *testing.T) {
+	cw := &countingWriter{}
+	w := NewWriter(cw)
+	for _, size := range []int{0, 10, singleWriteMax} {
+		w.Write(make([]byte, size))
+	}
+	if cw.writes != 3 {
+		t.Errorf("expect a write per small record, actual %d writes", cw.writes)
+	}
+	w.Write(make([]byte, singleWriteMax+1))
+	if cw.writes != 6 {
+		t.Errorf("expect 3 writes for large record, actual %d writes", cw.writes-3)
+	}
+}
+
+func TestWriteFrom(t *testing.T) {
+	payload := bytes.Repeat([]byte("0123456789"), 10000)
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	for _, p := range [][]byte{payload, nil} {
+		if n, err := w.WriteFrom(bytes.NewReader(p), uint64(len(p))); err != nil || n != int64(len(p)) {
+			t.Fatalf("expect %d bytes written, actual %d, %v", len(p), n, err)
+		}
+	}
+	expect := &bytes.Buffer{}
+	NewWriter(expect).Write(payload)
+	NewWriter(expect).Write(nil)
+	if !bytes.Equal(buf.Bytes(), expect.Bytes()) {
+		t.Errorf("WriteFrom output differs from Write")
+	}
+
+	if _, err := w.WriteFrom(bytes.NewReader(payload), uint64(len(payload))+1); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expect io.ErrUnexpectedEOF for short source, actual %v", err)
+	}
+}
+
+func TestWriteReadAll(t *testing.T) {
+	payloads := [][]byte{bytes.Repeat([]byte("0123456789"), 10000), nil, []byte("x")}
+	for _, opts := range [][]WriterOption{nil, {WithSequencePrefix()}, {WithRawWrites()}} {
+		buf, expect := &bytes.Buffer{}, &bytes.Buffer{}
+		w, ew := NewWriter(buf, opts...), NewWriter(expect, opts...)
+		for _, p := range payloads {
+			if n, err := w.WriteReadAll(bytes.NewReader(p)); err != nil || n != len(p) {
+				t.Fatalf("expect %d bytes written, actual %d, %v", len(p), n, err)
+			}
+			ew.Write(p)
+		}
+		if !bytes.Equal(buf.Bytes(), expect.Bytes()) {
+			t.Errorf("WriteReadAll output differs from Write with %d options", len(opts))
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf, WithMaxWriteSize(1000))
+	if _, err := w.WriteReadAll(bytes.NewReader(payloads[0])); !errors.Is(err, ErrRecordTooLarge) || buf.Len() != 0 {
+		t.Errorf("expect ErrRecordTooLarge with nothing written, actual %v, %d bytes", err, buf.Len())
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		w.WriteReadAll(bytes.NewReader(payloads[2]))
+	})
+	if allocs > 1 {
+		t.Errorf("expect buffer reused, actual %v allocs", allocs)
+	}
+}
+
+func TestWriteFramed(t *testing.T) {
+	data := writeSizedRecords(t, 3, 0, 5000, 7)
+	it := NewIterator(bytes.NewReader(data), 16, false)
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	for r, ok := it.NextRecord(); ok; r, ok = it.NextRecord() {
+		lengthCRC := binary.LittleEndian.Uint32(data[r.Offset+LengthSize:])
+		if n, err := w.WriteFramed(r.Payload, lengthCRC, r.DataCRC); err != nil || n != len(r.Payload) {
+			t.Fatalf("expect %d bytes written, actual %d, %v", len(r.Payload), n, err)
+		}
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("expect frames copied bit for bit")
+	}
+
+	buf.Reset()
+	w.WriteFramed([]byte("x"), checksum([]byte{1, 0, 0, 0, 0, 0, 0, 0}), 0)
+	if _, err := VerifyFile(buf); !errors.Is(err, ErrChecksum) {
+		t.Errorf("expect checksum error for wrong data CRC, actual %v", err)
+	}
+}
+
+func TestNextRecord(t *testing.T) {
+	f, err := os.Open("testdata/test.tfrecord")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	it := NewIterator(f, 0, false)
+	expect := []Record{
+		{0, 5, 0xbeb9ee8a, []byte("Hello")},
+		{21, 5, 0, []byte("World")},
+	}
+	for i, e := range expect {
+		r, ok := it.NextRecord()
+		if !ok {
+			t.Fatalf("unexpected stop at %d, err %v", i, it.Err())
+		}
+		if r.Offset != e.Offset || r.Length != e.Length || string(r.Payload) != string(e.Payload) {
+			t.Errorf("expect record %+v, actual %+v", e, *r)
+		}
+		if e.DataCRC != 0 && r.DataCRC != e.DataCRC {
+			t.Errorf("expect data CRC %#x, actual %#x", e.DataCRC, r.DataCRC)
+		}
+		if !ChecksumMatchesTF(r.Payload, r.DataCRC) {
+			t.Errorf("stored CRC %#x doesn't match payload %q", r.DataCRC, r.Payload)
+		}
+	}
+}
+
+func TestNewIteratorAt(t *testing.T) {
+	data := writeTestRecords(t, 10)
+	frameSize := int64(HeaderSize + 1 + FooterSize)
+	it := NewIteratorAt(bytes.NewReader(data), 3*frameSize, 16, true)
+	for i := 3; i < 10; i++ {
+		if !it.Next() || string(it.Value()) != strconv.Itoa(i) {
+			t.Fatalf("expect record %d, actual %q, err %v", i, it.Value(), it.Err())
+		}
+	}
+	if it.Next() || it.Err() != nil {
+		t.Errorf("expect clean EOF, actual %v", it.Err())
+	}
+	if s := it.State(); s.Offset != int64(len(data)) {
+		t.Errorf("expect offset %d, actual %d", len(data), s.Offset)
+	}
+
+	data[5*frameSize+HeaderSize]++
+	it = NewIteratorAt(bytes.NewReader(data), 3*frameSize, 16, true)
+	for it.Next() {
+	}
+	var re *RecordError
+	if !errors.As(it.Err(), &re) || re.Offset != 5*frameSize {
+		t.Errorf("expect error at offset %d, actual %v", 5*frameSize, it.Err())
+	}
+}
+
+func TestValueOwned(t *testing.T) {
+	data := writeSizedRecords(t, 3, 20, 3)
+	for _, c := range []struct {
+		name   string
+		opts   []Option
+		expect []bool
+	}{
+		{"allocate", nil, []bool{false, true, false}},
+		{"grow", []Option{WithOversizePolicy(OversizeGrow)}, []bool{false, false, false}},
+		{"readahead", []Option{WithReadahead(2)}, []bool{true, true, true}},
+	} {
+		it := NewIterator(bytes.NewReader(data), 16, true, c.opts...)
+		for i, expect := range c.expect {
+			if !it.Next() {
+				t.Fatalf("%s: unexpected stop, %v", c.name, it.Err())
+			}
+			if it.ValueOwned() != expect {
+				t.Errorf("%s: record %d, expect owned %v", c.name, i, expect)
+			}
+		}
+	}
+}
+
+// loopReader reads data over and over, so a single iterator keeps reading across benchmark or AllocsPerRun
+// runs.
+type loopReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *loopReader) Read(p []byte) (int, error) {
+	n := copy(p, r.data[r.pos:])
+	r.pos = (r.pos + n) % len(r.data)
+	return n, nil
+}
+
+func TestNextReuse(t *testing.T) {
+	data := writeTestRecords(t, 100)
+	it := NewIterator(&loopReader{data: data}, 16, true)
+	allocs := testing.AllocsPerRun(10, func() {
+		for i := 0; i < 100; i++ {
+			if record, ok := it.NextReuse(); !ok || string(record) != strconv.Itoa(i) {
+				t.Fatalf("expect record %d, actual %q, err %v", i, record, it.Err())
+			}
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("expect 0 allocs, actual %v", allocs)
+	}
+
+	it = NewIterator(bytes.NewReader(data), 1, true)
+	if record, ok := it.NextReuse(); !ok || string(record) != "0" {
+		t.Fatalf("expect record 0, actual %q, %v", record, ok)
+	}
+	for i := 1; i < 10; i++ {
+		if _, ok := it.NextReuse(); !ok {
+			t.Fatalf("unexpected stop at %d, err %v", i, it.Err())
+		}
+	}
+	if record, ok := it.NextReuse(); ok || record != nil {
+		t.Errorf("expect oversize record to stop iteration")
+	}
+	if !errors.Is(it.Err(), ErrBufferTooSmall) {
+		t.Errorf("expect ErrBufferTooSmall, actual %v", it.Err())
+	}
+}
+
+func TestAdaptiveBuffer(t *testing.T) {
+	sizes := make([]int, 100)
+	for i := range sizes {
+		sizes[i] = 256
+	}
+	sizes[50] = 1024
+	data := writeSizedRecords(t, sizes...)
+	it := NewIterator(&loopReader{data: data}, 0, true)
+	if !it.Next() || len(it.Value()) != 256 {
+		t.Fatalf("expect 256 bytes record, err %v", it.Err())
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		for i := 0; i < 100; i++ {
+			if !it.Next() {
+				t.Fatalf("unexpected stop at %d, err %v", i, it.Err())
+			}
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("expect 0 allocs, actual %v", allocs)
+	}
+	if len(it.preBuf) != 1024 {
+		t.Errorf("expect buffer grown to 1024 bytes, actual %d", len(it.preBuf))
+	}
+
+	it = NewIterator(bytes.NewReader(data), 0, true)
+	n := 0
+	for _, ok := it.NextReuse(); ok; _, ok = it.NextReuse() {
+		n++
+	}
+	if n != 100 || it.Err() != nil {
+		t.Errorf("expect NextReuse to grow buffer, actual %d records, err %v", n, it.Err())
+	}
+}
+
+func BenchmarkNextReuse(b *testing.B) {
+	it := NewIterator(&loopReader{data: writeTestRecords(b, 1000)}, 16, true)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, ok := it.NextReuse(); !ok {
+			b.Fatalf("unexpected stop, err %v", it.Err())
+		}
+	}
+}
+
+func TestChecksumMatchesTF(t *testing.T) {
+	// Vectors taken from testdata/test.tfrecord, written by tf.io.TFRecordWriter.
+	vectors := []struct {
+		payload []byte
+		crc     uint32
+	}{
+		{[]byte{5, 0, 0, 0, 0, 0, 0, 0}, 0x3e04b2ea},
+		{[]byte("Hello"), 0xbeb9ee8a},
+		{[]byte{10, 0, 0, 0, 0, 0, 0, 0}, 0x3abfa3ae},
+		{[]byte("Tensorflow"), 0x58702b8e},
+	}
+	for _, v := range vectors {
+		if !ChecksumMatchesTF(v.payload, v.crc) {
+			t.Errorf("checksum of %q doesn't match TF value %#x", v.payload, v.crc)
+		}
+		if ChecksumMatchesTF(v.payload, v.crc+1) {
+			t.Errorf("checksum of %q unexpectedly matches %#x", v.payload, v.crc+1)
+		}
+		if crc := MaskedCRC32C(v.payload); crc != v.crc {
+			t.Errorf("masked CRC of %q, expect %#x, actual %#x", v.payload, v.crc, crc)
+		}
+		// computed independently from the exposed table.
+		crc := crc32.Checksum(v.payload, CRCTable())
+		if masked := ((crc >> 15) | (crc << 17)) + 0xa282ead8; masked != v.crc {
+			t.Errorf("CRC of %q from CRCTable, expect %#x, actual %#x", v.payload, v.crc, masked)
+		}
+	}
+}
+
+func TestWriteMatchesTF(t *testing.T) {
+	expect, err := os.ReadFile("testdata/test.tfrecord")
+	if err != nil {
+		t.Fatalf("failed reading test file %v", err)
+	}
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	for _, r := range []string{"Hello", "World", "From", "Tensorflow"} {
+		if _, err := w.Write([]byte(r)); err != nil {
+			t.Fatalf("failed writing %s, %v", r, err)
+		}
+	}
+	if !bytes.Equal(buf.Bytes(), expect) {
+		t.Errorf("written bytes don't match file written by TF")
+	}
+}
+
+func TestReadCompressedFromTF(t *testing.T) {
+	for _, c := range []struct {
+		path string
+		open func(io.Reader) (io.Reader, error)
+	}{
+		{"testdata/test.tfrecord.gz", func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+		{"testdata/test.tfrecord.zlib", func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) }},
+	} {
+		f, err := os.Open(c.path)
+		if err != nil {
+			t.Fatalf("failed opening test file %v", err)
+		}
+		r, err := c.open(f)
+		if err != nil {
+			t.Fatalf("%s: failed opening decompressor %v", c.path, err)
+		}
+		out := ""
+		it := NewIterator(r, 1000, true)
+		for it.Next() {
+			out += string(it.Value())
+		}
+		f.Close()
+		if err := it.Err(); err != nil {
+			t.Fatalf("%s: read error %v", c.path, err)
+		}
+		if expect := "HelloWorldFromTensorflow"; out != expect {
+			t.Errorf("%s: unmatched read content, expect %s, actual %s", c.path, expect, out)
+		}
+	}
+}
+
+func TestRecordError(t *testing.T) {
+	data := writeTestRecords(t, 3)
+	frameSize := int64(HeaderSize + 1 + FooterSize)
+	data[2*frameSize+HeaderSize]++ // payload of record 2
+
+	it := NewIterator(bytes.NewReader(data), 16, true)
+	for it.Next() {
+	}
+	var re *RecordError
+	if !errors.As(it.Err(), &re) {
+		t.Fatalf("expect RecordError, actual %v", it.Err())
+	}
+	if re.Offset != 2*frameSize || re.Err != ErrChecksum {
+		t.Errorf("unexpected error %v", re)
+	}
+	if re.StoredCRC != checksum([]byte("2")) || re.ComputedCRC != checksum([]byte("3")) {
+		t.Errorf("unexpected CRCs in error %v", re)
+	}
+
+	data = writeTestRecords(t, 3)
+	data[frameSize]++ // length of record 1
+	it = NewIterator(bytes.NewReader(data), 16, true)
+	for it.Next() {
+	}
+	if !errors.As(it.Err(), &re) || re.Offset != frameSize || !errors.Is(re, ErrChecksum) {
+		t.Errorf("expect length checksum error at %d, actual %v", frameSize, it.Err())
+	}
+}
+
+func TestTruncatedHeader(t *testing.T) {
+	data := writeTestRecords(t, 2)
+	frameSize := HeaderSize + 1 + FooterSize
+	for _, c := range []struct {
+		name      string
+		tail      int
+		expectErr error
+	}{
+		{"no header", 0, nil},
+		{"partial header", 5, ErrTruncated},
+		{"one byte header", 1, ErrTruncated},
+		{"header without payload", HeaderSize, ErrTruncated},
+		{"payload without footer", HeaderSize + 1, ErrTruncated},
+		{"partial footer", HeaderSize + 1 + 2, ErrTruncated},
+	} {
+		it := NewIterator(bytes.NewReader(data[:frameSize+c.tail]), 16, true)
+		n := 0
+		for it.Next() {
+			n++
+		}
+		if n != 1 {
+			t.Errorf("%s: expect 1 record, actual %d", c.name, n)
+		}
+		if c.expectErr == nil {
+			if it.Err() != nil {
+				t.Errorf("%s: unexpected error %v", c.name, it.Err())
+			}
+			continue
+		}
+		if !errors.Is(it.Err(), c.expectErr) {
+			t.Errorf("%s: expect %v, actual %v", c.name, c.expectErr, it.Err())
+		}
+		var re *RecordError
+		if c.expectErr == ErrTruncated && (!errors.As(it.Err(), &re) || re.Offset != int64(frameSize)) {
+			t.Errorf("%s: expect offset %d in error %v", c.name, frameSize, it.Err())
+		}
+	}
+}
+
+func TestTrailingGarbage(t *testing.T) {
+	data := writeTestRecords(t, 2)
+	for _, junk := range [][]byte{{0}, []byte("junk"), bytes.Repeat([]byte{0}, 20), bytes.Repeat([]byte{0xff}, 40)} {
+		for _, checkDataCRC := range []bool{true, false} {
+			it := NewIterator(bytes.NewReader(append(append([]byte{}, data...), junk...)), 16, checkDataCRC)
+			n := 0
+			for it.Next() {
+				n++
+			}
+			var re *RecordError
+			if n != 2 || !errors.As(it.Err(), &re) || re.Offset != int64(len(data)) {
+				t.Errorf("%d junk bytes: expect error at offset %d after 2 records, actual %d, %v", len(junk), len(data), n, it.Err())
+			}
+		}
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	data := writeTestRecords(t, 5)
+	frameSize := HeaderSize + 1 + FooterSize
+	it := NewIterator(bytes.NewReader(data), 16, true)
+	it.Next()
+
+	buf := &bytes.Buffer{}
+	n, err := it.WriteTo(buf)
+	if err != nil || n != int64(4*frameSize) {
+		t.Fatalf("expect %d bytes written, actual %d, err %v", 4*frameSize, n, err)
+	}
+	if !bytes.Equal(buf.Bytes(), data[frameSize:]) {
+		t.Errorf("unmatched written content")
+	}
+
+	// data CRC of record 2 is repaired when not checked.
+	data[2*frameSize+HeaderSize+1]++
+	buf.Reset()
+	n, err = NewIterator(bytes.NewReader(data), 16, false).WriteTo(NewWriter(buf))
+	if err != nil || n != int64(len(data)) {
+		t.Fatalf("expect %d bytes written, actual %d, err %v", len(data), n, err)
+	}
+	if !bytes.Equal(buf.Bytes(), writeTestRecords(t, 5)) {
+		t.Errorf("unmatched written content")
+	}
+
+	// Writer options change frame size and add padding records, count is what the Writer emitted.
+	for _, opt := range []WriterOption{WithRawWrites(), WithoutDataCRC(), WithSequencePrefix(), WithAlignment(64)} {
+		buf.Reset()
+		n, err = NewIterator(bytes.NewReader(writeTestRecords(t, 5)), 16, true).WriteTo(NewWriter(buf, opt))
+		if err != nil || n != int64(buf.Len()) {
+			t.Errorf("expect %d bytes written, actual %d, err %v", buf.Len(), n, err)
+		}
+	}
+}
+
+func TestDrainTo(t *testing.T) {
+	data := writeTestRecords(t, 2500)
+	it := NewIterator(bytes.NewReader(data), 16, true)
+	for i := 0; i < 100; i++ {
+		it.Next()
+	}
+	buf := &bytes.Buffer{}
+	var calls []int
+	n, err := it.DrainTo(NewWriter(buf), func(n int) { calls = append(calls, n) })
+	if err != nil || n != 2400 {
+		t.Fatalf("expect 2400 records, actual %d, %v", n, err)
+	}
+	if len(calls) != 3 || calls[0] != 1000 || calls[2] != 2400 {
+		t.Errorf("unexpected progress calls %v", calls)
+	}
+	if frame := int(FrameSize(1)*10 + FrameSize(2)*90); !bytes.Equal(buf.Bytes(), data[frame:]) {
+		t.Errorf("unmatched drained content")
+	}
+
+	data[len(data)-1]++
+	it = NewIterator(bytes.NewReader(data), 16, true)
+	if n, err := it.DrainTo(NewWriter(io.Discard), nil); n != 2499 || !errors.Is(err, ErrChecksum) {
+		t.Errorf("expect ErrChecksum after 2499 records, actual %d, %v", n, err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	s := NewIterator(bytes.NewReader(writeTestRecords(t, 3)), 16, true)
+	out := ""
+	for s.Scan() {
+		out += string(s.Bytes())
+	}
+	if s.Err() != nil || out != "012" {
+		t.Errorf("unexpected content %s, err %v", out, s.Err())
+	}
+}
+
+func TestValidateLengthHeader(t *testing.T) {
+	// Headers taken from testdata/test.tfrecord, written by TensorFlow's C++ record writer.
+	vectors := [][]byte{
+		{0x05, 0, 0, 0, 0, 0, 0, 0, 0xea, 0xb2, 0x04, 0x3e},
+		{0x04, 0, 0, 0, 0, 0, 0, 0, 0x42, 0x45, 0x52, 0x04},
+		{0x0a, 0, 0, 0, 0, 0, 0, 0, 0xae, 0xa3, 0xbf, 0x3a},
+	}
+	for _, header := range vectors {
+		if err := ValidateLengthHeader(header); err != nil {
+			t.Errorf("header %x: unexpected error %v", header, err)
+		}
+		// the CRC covers exactly the 8 length bytes, any change in them must be caught.
+		for i := 0; i < LengthSize; i++ {
+			corrupt := append([]byte(nil), header...)
+			corrupt[i] ^= 0x80
+			if err := ValidateLengthHeader(corrupt); err != ErrChecksum {
+				t.Errorf("header %x: expect ErrChecksum, actual %v", corrupt, err)
+			}
+		}
+		var written [HeaderSize]byte
+		putHeader(written[:], uint64(header[0]))
+		if !bytes.Equal(written[:], header) {
+			t.Errorf("expect written header %x, actual %x", header, written)
+		}
+	}
+	if err := ValidateLengthHeader(vectors[0][:8]); err == nil {
+		t.Errorf("expect error for short header")
+	}
+}
+
+// countingWriter counts Write calls.
+type countingWriter struct {
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return len(p), nil
+}
+
+func benchmarkWrite(b *testing.B, size int, opts ...WriterOption) {
+	record := bytes.Repeat([]byte("x"), size)
+	cw := &countingWriter{}
+	w := NewWriter(cw, opts...)
+	b.ReportAllocs()
+	b.SetBytes(int64(size))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.Write(record)
+	}
+	b.ReportMetric(float64(cw.writes)/float64(b.N), "writes/op")
+}
+
+func BenchmarkWriteSmall(b *testing.B) {
+	benchmarkWrite(b, 100)
+}
+
+func BenchmarkWriteLarge(b *testing.B) {
+	benchmarkWrite(b, 64*1024)
+}
+
+func TestHugeLength(t *testing.T) {
+	data := make([]byte, HeaderSize+10)
+	putHeader(data, 1<<62)
+	for _, r := range []io.Reader{bytes.NewReader(data), struct{ io.Reader }{bytes.NewReader(data)}} {
+		if it := NewIterator(r, 16, true); it.Next() || !errors.Is(it.Err(), ErrRecordTooLarge) {
+			t.Errorf("expect ErrRecordTooLarge, actual %v", it.Err())
+		}
+	}
+	// a length just over the remaining input is caught before allocating when the reader knows its size.
+	putHeader(data, 1<<30)
+	if it := NewIterator(bytes.NewReader(data), 16, true); it.Next() || !errors.Is(it.Err(), ErrTruncated) {
+		t.Errorf("expect ErrTruncated, actual %v", it.Err())
+	}
+
+	putHeader(data, 1<<62)
+	lazy := NewLazyIterator(bytes.NewReader(data), true)
+	if !lazy.Next() {
+		t.Fatalf("expect lazy header read, err %v", lazy.Err())
+	}
+	if _, err := lazy.Record().Bytes(); !errors.Is(err, ErrRecordTooLarge) {
+		t.Errorf("expect ErrRecordTooLarge from LazyRecord, actual %v", err)
+	}
+	if _, err := NewIndexedReader(bytes.NewReader(data), true).ReadAt(RecordLocation{Length: 1 << 62}); !errors.Is(err, ErrRecordTooLarge) {
+		t.Errorf("expect ErrRecordTooLarge from IndexedReader, actual %v", err)
+	}
+}
+
+func FuzzIterator(f *testing.F) {
+	f.Add(writeTestRecords(f, 3))
+	f.Add(writeSizedRecords(f, 0, 100, 5))
+	if data, err := os.ReadFile("testdata/test.tfrecord"); err == nil {
+		f.Add(data)
+	}
+	huge := make([]byte, HeaderSize)
+	putHeader(huge, 1<<62)
+	f.Add(huge)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, opts := range [][]Option{nil, {WithMaxTotalBuffer(int64(len(data)) + 16)}} {
+			// a plain io.Reader hides the remaining size, corrupt lengths must still not panic or OOM.
+			for _, r := range []io.Reader{bytes.NewReader(data), struct{ io.Reader }{bytes.NewReader(data)}} {
+				it := NewIterator(r, 16, true, opts...)
+				out := &bytes.Buffer{}
+				w := NewWriter(out)
+				n := 0
+				for it.Next() {
+					w.Write(it.Value())
+					if n++; int64(n)*FrameSize(0) > int64(len(data)) {
+						t.Fatalf("%d records out of %d bytes", n, len(data))
+					}
+				}
+				if it.Err() == nil && !bytes.Equal(out.Bytes(), data) {
+					t.Errorf("clean read doesn't round trip")
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package tfrecord
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Trace makes the iterator log offset and payload length of each record it reads to w, one "offset length"
+// line per record, producing a trace ReplayTrace can turn back into the same scan. A failed write to w stops
+// iteration with the error. It returns it for chaining.
+func (it *Iterator) Trace(w io.Writer) *Iterator {
+	it.trace = w
+	return it
+}
+
+func (it *Iterator) traceRecord(offset int64, length int) error {
+	if _, err := fmt.Fprintf(it.trace, "%d %d\n", offset, length); err != nil {
+		return fmt.Errorf("write trace: %w", err)
+	}
+	return nil
+}
+
+// ReplayTrace returns an Iterator yielding records at the offsets and lengths logged in trace by Trace, for
+// deterministic tests. records are the payloads of the traced scan in order. Records must be contiguous in the
+// trace, the returned iterator fails with an error if trace is malformed, has gaps between records, or
+// doesn't match records.
+func ReplayTrace(records [][]byte, trace io.Reader) *Iterator {
+	data, start, err := replayTrace(records, trace)
+	if err != nil {
+		return NewIterator(&errReader{err}, 0, true)
+	}
+	it := NewIterator(bytes.NewReader(data), 0, true)
+	it.offset, it.readOffset, it.baseOffset = start, start, start
+	return it
+}
+
+func replayTrace(records [][]byte, trace io.Reader) ([]byte, int64, error) {
+	var (
+		buf   bytes.Buffer
+		w     = NewWriter(&buf)
+		start int64
+		next  int64
+		n     int
+	)
+	s := bufio.NewScanner(trace)
+	for ; s.Scan(); n++ {
+		var offset int64
+		var length int
+		if _, err := fmt.Sscanf(s.Text(), "%d %d", &offset, &length); err != nil {
+			return nil, 0, fmt.Errorf("trace line %d: %w", n+1, err)
+		}
+		switch {
+		case n >= len(records):
+			return nil, 0, fmt.Errorf("trace has more than %d records", len(records))
+		case n == 0:
+			start, next = offset, offset
+		case offset != next:
+			return nil, 0, fmt.Errorf("trace line %d: record at offset %d, expect %d after previous record", n+1, offset, next)
+		}
+		if length != len(records[n]) {
+			return nil, 0, fmt.Errorf("trace line %d: record of %d bytes, actual %d bytes", n+1, length, len(records[n]))
+		}
+		if _, err := w.Write(records[n]); err != nil {
+			return nil, 0, err
+		}
+		next += FrameSize(length)
+	}
+	if err := s.Err(); err != nil {
+		return nil, 0, fmt.Errorf("read trace: %w", err)
+	}
+	if n != len(records) {
+		return nil, 0, fmt.Errorf("trace has %d records, expect %d", n, len(records))
+	}
+	return buf.Bytes(), start, nil
+}
@@ -0,0 +1,65 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	data := writeSizedRecords(t, 3, 0, 10, 5)
+	var trace bytes.Buffer
+	it := NewIterator(bytes.NewReader(data), 16, true).Trace(&trace)
+	var records [][]byte
+	var offsets []int64
+	for it.Next() {
+		records = append(records, it.Value())
+		offsets = append(offsets, it.State().Offset)
+	}
+	if it.Err() != nil || len(records) != 4 {
+		t.Fatalf("expect 4 records, actual %d, err %v", len(records), it.Err())
+	}
+	if expect := "0 3\n19 0\n35 10\n61 5\n"; trace.String() != expect {
+		t.Fatalf("expect trace %q, actual %q", expect, trace.String())
+	}
+
+	it = ReplayTrace(records, bytes.NewReader(trace.Bytes()))
+	n := 0
+	for ; it.Next(); n++ {
+		if !bytes.Equal(it.Value(), records[n]) || it.State().Offset != offsets[n] {
+			t.Errorf("record %d replayed as %q at %d, expect %q at %d", n, it.Value(), it.State().Offset, records[n], offsets[n])
+		}
+	}
+	if it.Err() != nil || n != 4 {
+		t.Errorf("expect 4 replayed records, actual %d, err %v", n, it.Err())
+	}
+
+	it = ReplayTrace(records[2:], strings.NewReader("35 10\n61 5\n"))
+	if !it.Next() || it.State().Offset != 61 {
+		t.Errorf("expect replay from offset 35, err %v", it.Err())
+	}
+}
+
+func TestReplayTraceError(t *testing.T) {
+	records := [][]byte{[]byte("abc"), []byte("de")}
+	for _, trace := range []string{
+		"0 3\n",
+		"0 3\n19 2\n40 1\n",
+		"0 3\n20 2\n",
+		"0 3\n19 5\n",
+		"0 x\n",
+	} {
+		if it := ReplayTrace(records, strings.NewReader(trace)); it.Next() || it.Err() == nil {
+			t.Errorf("expect error replaying trace %q", trace)
+		}
+	}
+}
+
+func TestTraceWriteError(t *testing.T) {
+	it := NewIterator(bytes.NewReader(writeTestRecords(t, 3)), 16, true).Trace(failingWriter{io.ErrShortWrite})
+	if it.Next() || !errors.Is(it.Err(), io.ErrShortWrite) {
+		t.Errorf("expect trace write error, actual %v", it.Err())
+	}
+}
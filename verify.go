@@ -0,0 +1,77 @@
+package tfrecord
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+// VerifyFile reads all records in r and checks their CRCs, it returns number of valid records and the first
+// error found. Payloads are streamed through a small buffer, memory use doesn't depend on record size.
+func VerifyFile(r io.Reader) (int, error) {
+	it := NewIterator(r, 0, true)
+	n := 0
+	for {
+		if _, err := it.verifyNext(); err != nil {
+			return n, it.Err()
+		}
+		n++
+	}
+}
+
+// VerifyFileFull reads all records in r and reports offsets of all records failing data CRC check, unlike
+// VerifyFile it continues past them. records counts all records read, corrupt ones included. It stops on
+// errors that make following content unreadable, like length CRC failure or truncation. Like VerifyFile, it
+// uses constant memory.
+func VerifyFileFull(r io.Reader) (records int, corruptOffsets []int64, err error) {
+	it := NewIterator(r, 0, true, WithCorruptRecords(true))
+	for {
+		offset, err := it.verifyNext()
+		if err != nil {
+			return records, corruptOffsets, it.Err()
+		}
+		records++
+		if !it.LastCRCValid() {
+			corruptOffsets = append(corruptOffsets, offset)
+		}
+	}
+}
+
+// verifyNext streams next record through its CRC check and returns its offset, it returns io.EOF at clean end
+// of stream, otherwise errors are reported by Err.
+func (it *Iterator) verifyNext() (int64, error) {
+	payload, _, err := it.NextStream()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := io.Copy(io.Discard, payload); err != nil {
+		return 0, err
+	}
+	return it.recordOffset, nil
+}
+
+// VerifyAgainst checks the current record against expectedCRC, a masked CRC of its payload kept elsewhere like
+// in an index, to catch drift between the index and the data the CRC in the file can't. It returns a
+// *RecordError wrapping ErrChecksum on mismatch, with StoredCRC set to expectedCRC. The payload CRC checked by
+// the iterator is reused, otherwise CRC is computed. With WithSequenceNumbers CRC covers the prefix, like the
+// CRC in the file. It only works on records read by Next and NextReuse, not NextStream.
+func (it *Iterator) VerifyAgainst(expectedCRC uint32) error {
+	if it.value == nil {
+		return errors.New("no current record to verify")
+	}
+	crc := it.dataCRC
+	if !it.crcChecked {
+		if it.seqPrefix {
+			var prefix [sequenceSize]byte
+			binary.LittleEndian.PutUint64(prefix[:], it.sequence)
+			crc = maskCRC(crc32.Update(crc32.Checksum(prefix[:], crc32Table), crc32Table, it.value))
+		} else {
+			crc = it.dataChecksum(it.value)
+		}
+	}
+	if crc != expectedCRC {
+		return &RecordError{Offset: it.recordOffset, StoredCRC: expectedCRC, ComputedCRC: crc, Err: ErrChecksum}
+	}
+	return nil
+}
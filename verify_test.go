@@ -0,0 +1,86 @@
+package tfrecord
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestVerifyFile(t *testing.T) {
+	data := writeTestRecords(t, 5)
+	frameSize := HeaderSize + 1 + FooterSize
+	if n, err := VerifyFile(bytes.NewReader(data)); n != 5 || err != nil {
+		t.Errorf("expect 5 valid records, actual %d, err %v", n, err)
+	}
+	data[frameSize+HeaderSize] = 'x'
+	data[3*frameSize+HeaderSize] = 'x'
+	if n, err := VerifyFile(bytes.NewReader(data)); n != 1 || !errors.Is(err, ErrChecksum) {
+		t.Errorf("expect stop after 1 record, actual %d, err %v", n, err)
+	}
+}
+
+func TestVerifyFileFull(t *testing.T) {
+	data := writeTestRecords(t, 5)
+	frameSize := HeaderSize + 1 + FooterSize
+	data[frameSize+HeaderSize] = 'x'
+	data[3*frameSize+HeaderSize] = 'x'
+
+	n, offsets, err := VerifyFileFull(bytes.NewReader(data))
+	if n != 5 || err != nil {
+		t.Errorf("expect 5 records, actual %d, err %v", n, err)
+	}
+	if len(offsets) != 2 || offsets[0] != int64(frameSize) || offsets[1] != int64(3*frameSize) {
+		t.Errorf("unexpected corrupt offsets %v", offsets)
+	}
+
+	data[4*frameSize]++
+	n, offsets, err = VerifyFileFull(bytes.NewReader(data))
+	if n != 4 || len(offsets) != 2 || !errors.Is(err, ErrChecksum) {
+		t.Errorf("expect stop at length CRC failure, actual %d records, offsets %v, err %v", n, offsets, err)
+	}
+}
+
+func BenchmarkVerifyHugeRecord(b *testing.B) {
+	data := writeSizedRecords(b, 64<<20)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if n, err := VerifyFile(bytes.NewReader(data)); n != 1 || err != nil {
+			b.Fatalf("expect 1 valid record, actual %d, err %v", n, err)
+		}
+	}
+}
+
+func TestVerifyAgainst(t *testing.T) {
+	data := writeTestRecords(t, 3)
+	for _, check := range []bool{true, false} {
+		it := NewIterator(bytes.NewReader(data), 0, check)
+		if err := it.VerifyAgainst(0); err == nil {
+			t.Errorf("expect error without current record")
+		}
+		for it.Next() {
+			if err := it.VerifyAgainst(checksum(it.Value())); err != nil {
+				t.Errorf("expect record %q to match, actual %v", it.Value(), err)
+			}
+			var recordErr *RecordError
+			if err := it.VerifyAgainst(checksum([]byte("x"))); !errors.As(err, &recordErr) || !errors.Is(err, ErrChecksum) ||
+				recordErr.ComputedCRC != checksum(it.Value()) {
+				t.Errorf("expect checksum mismatch, actual %v", err)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, WithSequencePrefix())
+	w.Write([]byte("a"))
+	w.Write([]byte("b"))
+	for _, check := range []bool{true, false} {
+		it := NewIterator(bytes.NewReader(buf.Bytes()), 0, check, WithSequenceNumbers())
+		for it.Next() {
+			if err := it.VerifyAgainst(it.dataCRC); err != nil {
+				t.Errorf("expect CRC in file to match with sequence prefix, actual %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package tfrecord
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// WatchingIterator iterates records of files in a directory matching a pattern, in lexicographical order of
+// names, and keeps polling the directory for new files once all known ones are consumed, for streaming
+// ingestion of shards produced continuously. Each file is read once. Files must be complete when they appear
+// under a matching name, producers usually write to a temporary name then rename.
+type WatchingIterator struct {
+	ctx          context.Context
+	dir          string
+	pattern      string
+	interval     time.Duration
+	bufSize      int64
+	checkDataCRC bool
+
+	consumed map[string]bool
+	pending  []string
+	f        *os.File
+	it       *Iterator
+	err      error
+}
+
+// NewWatchingIterator creates a WatchingIterator over files in dir whose names match pattern, in the syntax
+// of filepath.Match. dir is polled every interval for new files when all known ones are consumed. Iteration
+// runs until ctx is done, Err then returns ctx.Err().
+func NewWatchingIterator(ctx context.Context, dir, pattern string, interval time.Duration, bufSize int64, checkDataCRC bool) *WatchingIterator {
+	return &WatchingIterator{
+		ctx:          ctx,
+		dir:          dir,
+		pattern:      pattern,
+		interval:     interval,
+		bufSize:      bufSize,
+		checkDataCRC: checkDataCRC,
+		consumed:     make(map[string]bool),
+	}
+}
+
+// Next moves to next record, blocking until one is available. It returns false when ctx is done or on error.
+func (w *WatchingIterator) Next() bool {
+	for w.err == nil {
+		if w.it != nil {
+			if w.it.Next() {
+				return true
+			}
+			err := w.it.Err()
+			w.f.Close()
+			if err != nil {
+				w.err = fmt.Errorf("%s: %w", w.f.Name(), err)
+				break
+			}
+			w.it, w.f = nil, nil
+		}
+		if err := w.ctx.Err(); err != nil {
+			w.err = err
+			break
+		}
+		if len(w.pending) == 0 {
+			if err := w.scan(); err != nil {
+				w.err = err
+				break
+			}
+		}
+		if len(w.pending) == 0 {
+			select {
+			case <-w.ctx.Done():
+			case <-time.After(w.interval):
+			}
+			continue
+		}
+		name := w.pending[0]
+		w.pending = w.pending[1:]
+		w.consumed[name] = true
+		f, err := os.Open(filepath.Join(w.dir, name))
+		if err != nil {
+			w.err = err
+			break
+		}
+		w.f, w.it = f, NewIterator(f, w.bufSize, w.checkDataCRC)
+	}
+	return false
+}
+
+// scan lists dir for matching files not consumed yet.
+func (w *WatchingIterator) scan() error {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || w.consumed[e.Name()] {
+			continue
+		}
+		ok, err := filepath.Match(w.pattern, e.Name())
+		if err != nil {
+			return err
+		}
+		if ok {
+			w.pending = append(w.pending, e.Name())
+		}
+	}
+	slices.Sort(w.pending)
+	return nil
+}
+
+// Value returns the current record, valid until next call to Next
+func (w *WatchingIterator) Value() []byte {
+	if w.it == nil {
+		return nil
+	}
+	return w.it.Value()
+}
+
+// Consumed returns names of files read so far, the one being read included.
+func (w *WatchingIterator) Consumed() []string {
+	names := make([]string, 0, len(w.consumed))
+	for name := range w.consumed {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Err returns the error stopping Next(), ctx.Err() when stopped by ctx
+func (w *WatchingIterator) Err() error {
+	return w.err
+}
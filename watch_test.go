@@ -0,0 +1,52 @@
+package tfrecord
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchingIterator(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, n int) {
+		tmp := filepath.Join(dir, "tmp")
+		if err := os.WriteFile(tmp, writeTestRecords(t, n), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("b.tfrecord", 2)
+	write("a.tfrecord", 1)
+	write("ignored.txt", 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := NewWatchingIterator(ctx, dir, "*.tfrecord", time.Millisecond, 16, true)
+	var values []string
+	for len(values) < 3 && w.Next() {
+		values = append(values, string(w.Value()))
+	}
+	if len(values) != 3 || values[0] != "0" || values[1] != "0" || values[2] != "1" {
+		t.Fatalf("unexpected records %v, %v", values, w.Err())
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		write("c.tfrecord", 1)
+	}()
+	if !w.Next() || string(w.Value()) != "0" {
+		t.Fatalf("expect record of new file, actual %q, %v", w.Value(), w.Err())
+	}
+	if consumed := w.Consumed(); len(consumed) != 3 || consumed[2] != "c.tfrecord" {
+		t.Errorf("unexpected consumed files %v", consumed)
+	}
+
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if w.Next() || w.Err() != context.Canceled {
+		t.Errorf("expect context.Canceled, actual %v", w.Err())
+	}
+}
@@ -0,0 +1,73 @@
+package tfrecord
+
+// WindowIterator iterates sliding windows of consecutive records from an Iterator, like tf.data.Dataset.window.
+// A window starts every stride records and holds up to windowSize records.
+type WindowIterator struct {
+	it            *Iterator
+	windowSize    int
+	stride        int
+	dropRemainder bool
+
+	buf     [][]byte
+	skip    int
+	started bool
+	eof     bool
+	value   [][]byte
+}
+
+// NewWindowIterator creates a WindowIterator reading records from it. Windows at the end which have less than
+// windowSize records are emitted unless dropRemainder is true. windowSize and stride less than 1 are treated
+// as 1.
+func NewWindowIterator(it *Iterator, windowSize, stride int, dropRemainder bool) *WindowIterator {
+	if windowSize < 1 {
+		windowSize = 1
+	}
+	if stride < 1 {
+		stride = 1
+	}
+	return &WindowIterator{it: it, windowSize: windowSize, stride: stride, dropRemainder: dropRemainder}
+}
+
+// Next moves to next window
+func (w *WindowIterator) Next() bool {
+	w.value = nil
+	if w.started {
+		if w.stride < len(w.buf) {
+			w.buf = append(w.buf[:0], w.buf[w.stride:]...)
+		} else {
+			w.skip = w.stride - len(w.buf)
+			w.buf = w.buf[:0]
+		}
+	}
+	w.started = true
+	for !w.eof && len(w.buf) < w.windowSize {
+		if !w.it.Next() {
+			w.eof = true
+			break
+		}
+		if w.skip > 0 {
+			w.skip--
+			continue
+		}
+		record := make([]byte, len(w.it.Value()))
+		copy(record, w.it.Value())
+		w.buf = append(w.buf, record)
+	}
+	if w.it.Err() != nil || len(w.buf) == 0 || (len(w.buf) < w.windowSize && w.dropRemainder) {
+		return false
+	}
+	w.value = make([][]byte, len(w.buf))
+	copy(w.value, w.buf)
+	return true
+}
+
+// Value returns the current window. Records are copies independent of the underlying iterator buffer and
+// shared between overlapping windows, they should be treated as read-only.
+func (w *WindowIterator) Value() [][]byte {
+	return w.value
+}
+
+// Err returns error of the underlying iterator
+func (w *WindowIterator) Err() error {
+	return w.it.Err()
+}
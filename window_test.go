@@ -0,0 +1,45 @@
+package tfrecord
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func readWindows(t *testing.T, n, size, stride int, dropRemainder bool) string {
+	it := NewIterator(bytes.NewReader(writeTestRecords(t, n)), 16, true)
+	w := NewWindowIterator(it, size, stride, dropRemainder)
+	var windows []string
+	for w.Next() {
+		var s []string
+		for _, r := range w.Value() {
+			s = append(s, string(r))
+		}
+		windows = append(windows, strings.Join(s, ""))
+	}
+	if err := w.Err(); err != nil {
+		t.Fatalf("read error %v", err)
+	}
+	return strings.Join(windows, ",")
+}
+
+func TestWindowIterator(t *testing.T) {
+	for _, c := range []struct {
+		n, size, stride int
+		drop            bool
+		expect          string
+	}{
+		{4, 3, 1, true, "012,123"},
+		{4, 3, 1, false, "012,123,23,3"},
+		{7, 2, 3, true, "01,34"},
+		{7, 2, 3, false, "01,34,6"},
+		{5, 2, 2, false, "01,23,4"},
+		{2, 3, 1, true, ""},
+		{0, 3, 1, false, ""},
+	} {
+		if actual := readWindows(t, c.n, c.size, c.stride, c.drop); actual != c.expect {
+			t.Errorf("%d records, size %d, stride %d, drop %v: expect %q, actual %q",
+				c.n, c.size, c.stride, c.drop, c.expect, actual)
+		}
+	}
+}